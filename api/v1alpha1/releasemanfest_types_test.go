@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestArch_Short(t *testing.T) {
@@ -62,3 +64,274 @@ func TestConvertContainersSliceToMap(t *testing.T) {
 	m = component.ConvertContainerSliceToMap()
 	assert.Empty(t, m)
 }
+
+func TestKubernetes_Distribution(t *testing.T) {
+	kubernetes := &Kubernetes{
+		K3S:  KubernetesDistribution{Version: "v1.31.3+k3s1"},
+		RKE2: KubernetesDistribution{Version: "v1.31.3+rke2r1"},
+	}
+
+	distribution, err := kubernetes.Distribution("v1.30.3+k3s1")
+	require.NoError(t, err)
+	assert.Equal(t, &kubernetes.K3S, distribution)
+
+	distribution, err = kubernetes.Distribution("v1.30.3+rke2r1")
+	require.NoError(t, err)
+	assert.Equal(t, &kubernetes.RKE2, distribution)
+
+	_, err = kubernetes.Distribution("v1.30.3")
+	assert.EqualError(t, err, "unsupported kubernetes distribution detected in version v1.30.3")
+}
+
+func TestKubernetesDistribution_ValidateVersionSkew(t *testing.T) {
+	node := func(name, kubeletVersion string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		target        string
+		nodes         []corev1.Node
+		expectedError string
+	}{
+		{
+			name:   "Patch upgrade",
+			target: "v1.30.5+rke2r1",
+			nodes:  []corev1.Node{node("node-1", "v1.30.3+rke2r1")},
+		},
+		{
+			name:   "Minor upgrade",
+			target: "v1.31.3+rke2r1",
+			nodes:  []corev1.Node{node("node-1", "v1.30.3+rke2r1"), node("node-2", "v1.30.3+rke2r1")},
+		},
+		{
+			name:   "Partially upgraded nodes",
+			target: "v1.31.3+rke2r1",
+			nodes:  []corev1.Node{node("node-1", "v1.31.3+rke2r1"), node("node-2", "v1.30.3+rke2r1")},
+		},
+		{
+			name:   "Same version",
+			target: "v1.31.3+k3s1",
+			nodes:  []corev1.Node{node("node-1", "v1.31.3+k3s1")},
+		},
+		{
+			name:          "Skipped minor version",
+			target:        "v1.32.1+rke2r1",
+			nodes:         []corev1.Node{node("node-1", "v1.31.3+rke2r1"), node("node-2", "v1.30.3+rke2r1")},
+			expectedError: "upgrading node node-2 from v1.30.3+rke2r1 to v1.32.1+rke2r1 skips a minor version which is not supported",
+		},
+		{
+			name:          "Major upgrade",
+			target:        "v2.0.0+k3s1",
+			nodes:         []corev1.Node{node("node-1", "v1.31.3+k3s1")},
+			expectedError: "upgrading node node-1 from v1.31.3+k3s1 to v2.0.0+k3s1 skips a minor version which is not supported",
+		},
+		{
+			name:          "Patch downgrade",
+			target:        "v1.31.1+k3s1",
+			nodes:         []corev1.Node{node("node-1", "v1.31.3+k3s1")},
+			expectedError: "downgrading node node-1 from v1.31.3+k3s1 to v1.31.1+k3s1 is not supported",
+		},
+		{
+			name:          "Minor downgrade",
+			target:        "v1.30.3+k3s1",
+			nodes:         []corev1.Node{node("node-1", "v1.31.3+k3s1")},
+			expectedError: "downgrading node node-1 from v1.31.3+k3s1 to v1.30.3+k3s1 is not supported",
+		},
+		{
+			name:          "Invalid kubelet version",
+			target:        "v1.31.3+k3s1",
+			nodes:         []corev1.Node{node("node-1", "unknown")},
+			expectedError: "parsing kubelet version of node node-1: could not parse \"unknown\" as version",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			distribution := &KubernetesDistribution{Version: test.target}
+
+			err := distribution.ValidateVersionSkew(test.nodes)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestCompatibility_ValidateUpgradeFrom(t *testing.T) {
+	tests := []struct {
+		name           string
+		compatibility  *Compatibility
+		releaseVersion string
+		expectedError  string
+	}{
+		{
+			name:           "No compatibility metadata",
+			releaseVersion: "3.0.0",
+		},
+		{
+			name:          "Unknown release version",
+			compatibility: &Compatibility{MinimumReleaseVersion: "3.2.0"},
+		},
+		{
+			name:           "Minimum release version",
+			compatibility:  &Compatibility{MinimumReleaseVersion: "3.2.0"},
+			releaseVersion: "3.2.0",
+		},
+		{
+			name:           "Release version below minimum",
+			compatibility:  &Compatibility{MinimumReleaseVersion: "3.2.0"},
+			releaseVersion: "3.0.0",
+			expectedError:  "upgrading from release 3.0.0 is not supported, minimum release version is 3.2.0",
+		},
+		{
+			name:           "Supported upgrade path",
+			compatibility:  &Compatibility{UpgradePaths: []string{"3.1.0", "v3.2.0"}},
+			releaseVersion: "3.2.0",
+		},
+		{
+			name:           "Unsupported upgrade path",
+			compatibility:  &Compatibility{UpgradePaths: []string{"3.1.0", "3.2.0"}},
+			releaseVersion: "3.0.0",
+			expectedError:  "upgrading from release 3.0.0 is not supported, supported upgrade paths are from [3.1.0 3.2.0]",
+		},
+		{
+			name:           "Invalid release version",
+			compatibility:  &Compatibility{UpgradePaths: []string{"3.1.0"}},
+			releaseVersion: "latest",
+			expectedError:  "parsing release version: could not parse \"latest\" as version",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.compatibility.ValidateUpgradeFrom(test.releaseVersion)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestCompatibility_ValidateNodes(t *testing.T) {
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.31.3+rke2r1"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.5+rke2r1"}},
+		},
+	}
+
+	var compatibility *Compatibility
+	assert.NoError(t, compatibility.ValidateNodes(nodes))
+
+	compatibility = &Compatibility{MinimumKubernetesVersion: "v1.30.0"}
+	assert.NoError(t, compatibility.ValidateNodes(nodes))
+
+	compatibility = &Compatibility{MinimumKubernetesVersion: "v1.31.0"}
+	assert.EqualError(t, compatibility.ValidateNodes(nodes),
+		"node node-2 is running kubernetes v1.30.5+rke2r1, minimum supported version is v1.31.0")
+}
+
+func TestCompatibility_IsDeprecated(t *testing.T) {
+	var compatibility *Compatibility
+	assert.False(t, compatibility.IsDeprecated("3.1.0"))
+
+	compatibility = &Compatibility{DeprecatedReleaseVersions: []string{"3.1.0", "v3.1.1"}}
+	assert.True(t, compatibility.IsDeprecated("3.1.0"))
+	assert.True(t, compatibility.IsDeprecated("3.1.1"))
+	assert.False(t, compatibility.IsDeprecated("3.1.2"))
+	assert.False(t, compatibility.IsDeprecated("latest"))
+}
+
+func TestUpgradePath(t *testing.T) {
+	manifest := func(releaseVersion string, compatibility *Compatibility) ReleaseManifest {
+		return ReleaseManifest{
+			Spec: ReleaseManifestSpec{
+				ReleaseVersion: releaseVersion,
+				Compatibility:  compatibility,
+			},
+		}
+	}
+
+	target := manifest("3.2.0", &Compatibility{MinimumReleaseVersion: "3.1.0"})
+
+	tests := []struct {
+		name           string
+		releaseVersion string
+		manifests      []ReleaseManifest
+		expectedPath   []string
+	}{
+		{
+			name:           "Direct upgrade",
+			releaseVersion: "3.1.1",
+			expectedPath:   []string{"3.2.0"},
+		},
+		{
+			name:           "Single intermediate release",
+			releaseVersion: "3.0.2",
+			manifests: []ReleaseManifest{
+				manifest("3.0.2", nil),
+				manifest("3.1.0", &Compatibility{MinimumReleaseVersion: "3.0.0"}),
+				target,
+			},
+			expectedPath: []string{"3.1.0", "3.2.0"},
+		},
+		{
+			name:           "Fewest hops",
+			releaseVersion: "3.0.2",
+			manifests: []ReleaseManifest{
+				manifest("3.1.2", &Compatibility{MinimumReleaseVersion: "3.0.0"}),
+				manifest("3.0.3", nil),
+				manifest("3.1.0", &Compatibility{UpgradePaths: []string{"3.0.3"}}),
+			},
+			expectedPath: []string{"3.1.2", "3.2.0"},
+		},
+		{
+			name:           "Multiple intermediate releases",
+			releaseVersion: "2.9.0",
+			manifests: []ReleaseManifest{
+				manifest("3.0.0", &Compatibility{UpgradePaths: []string{"2.9.0"}}),
+				manifest("3.1.0", &Compatibility{MinimumReleaseVersion: "3.0.0"}),
+			},
+			expectedPath: []string{"3.0.0", "3.1.0", "3.2.0"},
+		},
+		{
+			name:           "Intermediate releases are not downgraded to",
+			releaseVersion: "3.0.2",
+			manifests: []ReleaseManifest{
+				manifest("3.0.1", nil),
+				manifest("3.1.0", &Compatibility{UpgradePaths: []string{"3.0.1"}}),
+			},
+		},
+		{
+			name:           "No intermediate release available",
+			releaseVersion: "3.0.2",
+			manifests: []ReleaseManifest{
+				manifest("3.3.0", nil),
+			},
+		},
+		{
+			name:           "Invalid release version",
+			releaseVersion: "latest",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedPath, UpgradePath(test.releaseVersion, &target, test.manifests))
+		})
+	}
+}
@@ -18,9 +18,13 @@ package v1alpha1
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
 )
 
 const (
@@ -58,6 +62,182 @@ func SupportedArchitectures(architectures []Arch) map[string]struct{} {
 type ReleaseManifestSpec struct {
 	ReleaseVersion string     `json:"releaseVersion"`
 	Components     Components `json:"components,omitempty"`
+	// Compatibility restricts which clusters can be upgraded to the release.
+	// +optional
+	Compatibility *Compatibility `json:"compatibility,omitempty"`
+}
+
+type Compatibility struct {
+	// MinimumReleaseVersion is the earliest release version which can be upgraded to the release.
+	// +optional
+	MinimumReleaseVersion string `json:"minimumReleaseVersion,omitempty"`
+	// UpgradePaths lists the release versions which can be upgraded to the release.
+	// Upgrades from any other release version are not supported if set.
+	// +optional
+	UpgradePaths []string `json:"upgradePaths,omitempty"`
+	// MinimumKubernetesVersion is the earliest Kubernetes version the cluster nodes
+	// must be running in order to be upgraded to the release.
+	// +optional
+	MinimumKubernetesVersion string `json:"minimumKubernetesVersion,omitempty"`
+	// DeprecatedReleaseVersions lists release versions which are deprecated.
+	// Upgrades to deprecated releases are still possible but discouraged.
+	// +optional
+	DeprecatedReleaseVersions []string `json:"deprecatedReleaseVersions,omitempty"`
+}
+
+// ValidateUpgradeFrom verifies that the specified release version can be upgraded to the release.
+// Upgrades from an unknown release version are not validated.
+func (c *Compatibility) ValidateUpgradeFrom(releaseVersion string) error {
+	if c == nil || releaseVersion == "" {
+		return nil
+	}
+
+	current, err := version.ParseSemantic(releaseVersion)
+	if err != nil {
+		return fmt.Errorf("parsing release version: %w", err)
+	}
+
+	if c.MinimumReleaseVersion != "" {
+		minimum, err := version.ParseSemantic(c.MinimumReleaseVersion)
+		if err != nil {
+			return fmt.Errorf("parsing minimum release version: %w", err)
+		}
+
+		if current.LessThan(minimum) {
+			return fmt.Errorf("upgrading from release %s is not supported, minimum release version is %s",
+				releaseVersion, c.MinimumReleaseVersion)
+		}
+	}
+
+	if len(c.UpgradePaths) != 0 && !containsVersion(c.UpgradePaths, current) {
+		return fmt.Errorf("upgrading from release %s is not supported, supported upgrade paths are from %v",
+			releaseVersion, c.UpgradePaths)
+	}
+
+	return nil
+}
+
+// ValidateNodes verifies that the cluster nodes are running a Kubernetes version
+// which can be upgraded to the release.
+func (c *Compatibility) ValidateNodes(nodes []corev1.Node) error {
+	if c == nil || c.MinimumKubernetesVersion == "" {
+		return nil
+	}
+
+	minimum, err := version.ParseSemantic(c.MinimumKubernetesVersion)
+	if err != nil {
+		return fmt.Errorf("parsing minimum kubernetes version: %w", err)
+	}
+
+	for _, node := range nodes {
+		current, err := version.ParseSemantic(node.Status.NodeInfo.KubeletVersion)
+		if err != nil {
+			return fmt.Errorf("parsing kubelet version of node %s: %w", node.Name, err)
+		}
+
+		if current.LessThan(minimum) {
+			return fmt.Errorf("node %s is running kubernetes %s, minimum supported version is %s",
+				node.Name, node.Status.NodeInfo.KubeletVersion, c.MinimumKubernetesVersion)
+		}
+	}
+
+	return nil
+}
+
+// IsDeprecated returns whether the specified release version is deprecated.
+func (c *Compatibility) IsDeprecated(releaseVersion string) bool {
+	if c == nil {
+		return false
+	}
+
+	v, err := version.ParseSemantic(releaseVersion)
+	if err != nil {
+		return false
+	}
+
+	return containsVersion(c.DeprecatedReleaseVersions, v)
+}
+
+// UpgradePath returns the shortest chain of release versions an upgrade from the specified release version
+// to the target release has to go through, ending with the target release version.
+// Intermediate releases are chosen from the specified manifests according to their compatibility metadata.
+// Returns nil if the target release can not be reached.
+func UpgradePath(releaseVersion string, target *ReleaseManifest, manifests []ReleaseManifest) []string {
+	type release struct {
+		manifest *ReleaseManifest
+		version  *version.Version
+	}
+
+	from, err := version.ParseSemantic(releaseVersion)
+	if err != nil {
+		return nil
+	}
+
+	targetVersion, err := version.ParseSemantic(target.Spec.ReleaseVersion)
+	if err != nil {
+		return nil
+	}
+
+	releases := []release{{manifest: target, version: targetVersion}}
+	for i := range manifests {
+		v, err := version.ParseSemantic(manifests[i].Spec.ReleaseVersion)
+		if err == nil && v.GreaterThan(from) && v.LessThan(targetVersion) {
+			releases = append(releases, release{manifest: &manifests[i], version: v})
+		}
+	}
+
+	// A breadth-first search yields the path with the fewest hops.
+	slices.SortFunc(releases, func(a, b release) int {
+		switch {
+		case a.version.LessThan(b.version):
+			return -1
+		case a.version.GreaterThan(b.version):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	previous := map[*ReleaseManifest]*ReleaseManifest{}
+	root := &ReleaseManifest{Spec: ReleaseManifestSpec{ReleaseVersion: releaseVersion}}
+	queue := []release{{manifest: root, version: from}}
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range releases {
+			if _, visited := previous[next.manifest]; visited || !next.version.GreaterThan(current.version) {
+				continue
+			}
+
+			if next.manifest.Spec.Compatibility.ValidateUpgradeFrom(current.manifest.Spec.ReleaseVersion) != nil {
+				continue
+			}
+
+			previous[next.manifest] = current.manifest
+			if next.manifest != target {
+				queue = append(queue, next)
+				continue
+			}
+
+			var path []string
+			for m := target; m != root; m = previous[m] {
+				path = append([]string{m.Spec.ReleaseVersion}, path...)
+			}
+
+			return path
+		}
+	}
+
+	return nil
+}
+
+func containsVersion(versions []string, v *version.Version) bool {
+	return slices.ContainsFunc(versions, func(s string) bool {
+		other, err := version.ParseSemantic(s)
+		return err == nil && v.EqualTo(other)
+	})
 }
 
 // ReleaseManifestStatus defines the observed state of ReleaseManifest
@@ -95,11 +275,50 @@ type Kubernetes struct {
 	RKE2 KubernetesDistribution `json:"rke2"`
 }
 
+// Distribution returns the distribution matching the one the specified kubelet version belongs to.
+func (k *Kubernetes) Distribution(kubeletVersion string) (*KubernetesDistribution, error) {
+	switch {
+	case strings.Contains(kubeletVersion, "k3s"):
+		return &k.K3S, nil
+	case strings.Contains(kubeletVersion, "rke2"):
+		return &k.RKE2, nil
+	default:
+		return nil, fmt.Errorf("unsupported kubernetes distribution detected in version %s", kubeletVersion)
+	}
+}
+
 type KubernetesDistribution struct {
 	Version        string          `json:"version"`
 	CoreComponents []CoreComponent `json:"coreComponents,omitempty"`
 }
 
+// ValidateVersionSkew verifies that the nodes can be upgraded to the distribution version
+// without downgrading them or skipping a minor Kubernetes version.
+func (d *KubernetesDistribution) ValidateVersionSkew(nodes []corev1.Node) error {
+	target, err := version.ParseSemantic(d.Version)
+	if err != nil {
+		return fmt.Errorf("parsing target kubernetes version: %w", err)
+	}
+
+	for _, node := range nodes {
+		current, err := version.ParseSemantic(node.Status.NodeInfo.KubeletVersion)
+		if err != nil {
+			return fmt.Errorf("parsing kubelet version of node %s: %w", node.Name, err)
+		}
+
+		switch {
+		case target.LessThan(current):
+			return fmt.Errorf("downgrading node %s from %s to %s is not supported",
+				node.Name, node.Status.NodeInfo.KubeletVersion, d.Version)
+		case target.Major() != current.Major() || target.Minor() > current.Minor()+1:
+			return fmt.Errorf("upgrading node %s from %s to %s skips a minor version which is not supported",
+				node.Name, node.Status.NodeInfo.KubeletVersion, d.Version)
+		}
+	}
+
+	return nil
+}
+
 // +kubebuilder:validation:Enum=HelmChart;Deployment
 type CoreComponentType string
 
@@ -0,0 +1,248 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// +kubebuilder:webhook:path=/validate-lifecycle-suse-com-v1alpha1-releasemanifest,mutating=false,failurePolicy=fail,sideEffects=None,groups=lifecycle.suse.com,resources=releasemanifests,verbs=create;update,versions=v1alpha1,name=vreleasemanifest.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &ReleaseManifestValidator{}
+
+type ReleaseManifestValidator struct {
+	// Client is used to look up the upgrade plans referencing the release manifests.
+	// Immutability of applied release manifests is not enforced if unset.
+	Client client.Reader
+}
+
+func (v *ReleaseManifestValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	manifest, ok := obj.(*ReleaseManifest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type: %T", obj)
+	}
+
+	return nil, validateReleaseManifestSpec(&manifest.Spec)
+}
+
+func (v *ReleaseManifestValidator) ValidateUpdate(ctx context.Context, old, new runtime.Object) (admission.Warnings, error) {
+	oldManifest, ok := old.(*ReleaseManifest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type: %T", old)
+	}
+
+	newManifest, ok := new.(*ReleaseManifest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type: %T", new)
+	}
+
+	if equality.Semantic.DeepEqual(oldManifest.Spec, newManifest.Spec) {
+		return nil, nil
+	}
+
+	if err := v.validateNotApplied(ctx, oldManifest); err != nil {
+		return nil, err
+	}
+
+	return nil, validateReleaseManifestSpec(&newManifest.Spec)
+}
+
+func (v *ReleaseManifestValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateNotApplied rejects changes to release manifests which have been successfully applied by an upgrade plan.
+func (v *ReleaseManifestValidator) validateNotApplied(ctx context.Context, manifest *ReleaseManifest) error {
+	if v.Client == nil {
+		return nil
+	}
+
+	plans := &UpgradePlanList{}
+	if err := v.Client.List(ctx, plans, client.InNamespace(manifest.Namespace)); err != nil {
+		return fmt.Errorf("listing upgrade plans: %w", err)
+	}
+
+	for _, plan := range plans.Items {
+		if plan.HasApplied(manifest.Spec.ReleaseVersion) {
+			return fmt.Errorf("release manifest '%s' cannot be modified as it has been applied by upgrade plan '%s'",
+				manifest.Name, plan.Name)
+		}
+	}
+
+	return nil
+}
+
+func validateReleaseManifestSpec(spec *ReleaseManifestSpec) error {
+	if _, err := validateReleaseVersion(spec.ReleaseVersion); err != nil {
+		return err
+	}
+
+	if err := validateOperatingSystem(&spec.Components.OperatingSystem); err != nil {
+		return err
+	}
+
+	if err := validateKubernetesDistribution("k3s", &spec.Components.Kubernetes.K3S); err != nil {
+		return err
+	}
+
+	if err := validateKubernetesDistribution("rke2", &spec.Components.Kubernetes.RKE2); err != nil {
+		return err
+	}
+
+	if err := validateHelmCharts(spec.Components.Workloads.Helm); err != nil {
+		return err
+	}
+
+	return validateCompatibility(spec.Compatibility)
+}
+
+func validateOperatingSystem(operatingSystem *OperatingSystem) error {
+	if len(operatingSystem.SupportedArchs) == 0 {
+		return fmt.Errorf("at least one supported architecture must be specified")
+	}
+
+	for _, arch := range operatingSystem.SupportedArchs {
+		if arch != ArchTypeX86 && arch != ArchTypeARM {
+			return fmt.Errorf("'%s' is not a supported architecture, must be one of %s or %s", arch, ArchTypeX86, ArchTypeARM)
+		}
+	}
+
+	return nil
+}
+
+func validateKubernetesDistribution(name string, distribution *KubernetesDistribution) error {
+	if distribution.Version == "" {
+		return fmt.Errorf("%s version is required", name)
+	}
+
+	if _, err := version.ParseSemantic(distribution.Version); err != nil {
+		return fmt.Errorf("'%s' is not a valid %s version", distribution.Version, name)
+	}
+
+	if !strings.Contains(distribution.Version, "+"+name) {
+		return fmt.Errorf("'%s' is not a valid %s version, expected a '+%s' suffix", distribution.Version, name, name)
+	}
+
+	for _, component := range distribution.CoreComponents {
+		if component.Name == "" {
+			return fmt.Errorf("%s core component name is required", name)
+		}
+
+		switch component.Type {
+		case HelmChartType:
+			if component.Version == "" {
+				return fmt.Errorf("version is required for %s core component '%s' of type %s", name, component.Name, component.Type)
+			}
+		case DeploymentType:
+			if len(component.Containers) == 0 {
+				return fmt.Errorf("containers are required for %s core component '%s' of type %s", name, component.Name, component.Type)
+			}
+
+			for _, container := range component.Containers {
+				if container.Name == "" || container.Image == "" {
+					return fmt.Errorf("name and image are required for the containers of %s core component '%s'", name, component.Name)
+				}
+			}
+		default:
+			return fmt.Errorf("'%s' is not a supported type of %s core component '%s'", component.Type, name, component.Name)
+		}
+	}
+
+	return nil
+}
+
+func validateHelmCharts(charts []HelmChart) error {
+	var releaseNames, prettyNames []string
+
+	validateChart := func(chart *HelmChart) error {
+		if chart.ReleaseName == "" {
+			return fmt.Errorf("release name is required for helm chart '%s'", chart.Name)
+		}
+
+		if chart.Name == "" || chart.Version == "" {
+			return fmt.Errorf("chart name and version are required for helm chart '%s'", chart.ReleaseName)
+		}
+
+		if slices.Contains(releaseNames, chart.ReleaseName) {
+			return fmt.Errorf("helm chart release name '%s' is specified more than once", chart.ReleaseName)
+		}
+		releaseNames = append(releaseNames, chart.ReleaseName)
+
+		return nil
+	}
+
+	for _, chart := range charts {
+		if err := validateChart(&chart); err != nil {
+			return err
+		}
+
+		// Pretty names identify the upgrade conditions of the charts.
+		if chart.PrettyName == "" {
+			return fmt.Errorf("pretty name is required for helm chart '%s'", chart.ReleaseName)
+		}
+
+		if slices.Contains(prettyNames, chart.PrettyName) {
+			return fmt.Errorf("helm chart pretty name '%s' is specified more than once", chart.PrettyName)
+		}
+		prettyNames = append(prettyNames, chart.PrettyName)
+
+		for _, additional := range slices.Concat(chart.DependencyCharts, chart.AddonCharts) {
+			if err := validateChart(&additional); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateCompatibility(compatibility *Compatibility) error {
+	if compatibility == nil {
+		return nil
+	}
+
+	versions := slices.Concat(compatibility.UpgradePaths, compatibility.DeprecatedReleaseVersions)
+	if compatibility.MinimumReleaseVersion != "" {
+		versions = append(versions, compatibility.MinimumReleaseVersion)
+	}
+
+	for _, v := range versions {
+		if _, err := version.ParseSemantic(v); err != nil {
+			return fmt.Errorf("'%s' is not a semantic version", v)
+		}
+	}
+
+	if v := compatibility.MinimumKubernetesVersion; v != "" {
+		if _, err := version.ParseSemantic(v); err != nil {
+			return fmt.Errorf("'%s' is not a valid minimum kubernetes version", v)
+		}
+	}
+
+	return nil
+}
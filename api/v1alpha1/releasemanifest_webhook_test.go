@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newReleaseManifest(name, releaseVersion string) *ReleaseManifest {
+	return &ReleaseManifest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: ReleaseManifestSpec{
+			ReleaseVersion: releaseVersion,
+			Components: Components{
+				Kubernetes: Kubernetes{
+					K3S:  KubernetesDistribution{Version: "v1.30.3+k3s1"},
+					RKE2: KubernetesDistribution{Version: "v1.30.3+rke2r1"},
+				},
+				OperatingSystem: OperatingSystem{
+					Version:        "6.0",
+					SupportedArchs: []Arch{ArchTypeX86},
+					PrettyName:     "SUSE Linux Micro 6.0",
+				},
+				Workloads: Workloads{
+					Helm: []HelmChart{
+						{ReleaseName: "rancher", Name: "rancher", Version: "v2.8.5", PrettyName: "Rancher"},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("ReleaseManifest Webhook", func() {
+	Context("When creating ReleaseManifests under Validating Webhook", func() {
+		It("Should be admitted if the manifest is valid", func() {
+			manifest := newReleaseManifest("release-manifest-valid", "3.1.0")
+
+			Expect(k8sClient.Create(ctx, manifest)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, manifest)).To(Succeed())
+		})
+
+		It("Should be denied if release version is not in semantic format", func() {
+			manifest := newReleaseManifest("release-manifest-invalid", "3.1")
+
+			err := k8sClient.Create(ctx, manifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("'3.1' is not a semantic version")))
+		})
+
+		It("Should be denied if the kubernetes version does not match the distribution", func() {
+			manifest := newReleaseManifest("release-manifest-invalid", "3.1.0")
+			manifest.Spec.Components.Kubernetes.RKE2.Version = "v1.30.3+k3s1"
+
+			err := k8sClient.Create(ctx, manifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("'v1.30.3+k3s1' is not a valid rke2 version, expected a '+rke2' suffix")))
+		})
+
+		It("Should be denied if helm chart release names are not unique", func() {
+			manifest := newReleaseManifest("release-manifest-invalid", "3.1.0")
+			manifest.Spec.Components.Workloads.Helm[0].AddonCharts = []HelmChart{
+				{ReleaseName: "rancher", Name: "rancher-turtles", Version: "0.2.0"},
+			}
+
+			err := k8sClient.Create(ctx, manifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("helm chart release name 'rancher' is specified more than once")))
+		})
+
+		It("Should be denied if a core component is missing required fields", func() {
+			manifest := newReleaseManifest("release-manifest-invalid", "3.1.0")
+			manifest.Spec.Components.Kubernetes.K3S.CoreComponents = []CoreComponent{
+				{Name: "traefik", Type: DeploymentType},
+			}
+
+			err := k8sClient.Create(ctx, manifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("containers are required for k3s core component 'traefik' of type Deployment")))
+		})
+	})
+
+	Context("When updating ReleaseManifests under Validating Webhook", Ordered, func() {
+		manifest := newReleaseManifest("release-manifest-3-1-0", "3.1.0")
+
+		plan := &UpgradePlan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "plan-release-manifest",
+				Namespace: "default",
+			},
+			Spec: UpgradePlanSpec{
+				ReleaseVersion: "3.1.0",
+			},
+		}
+
+		BeforeAll(func() {
+			By("Creating the manifest and the plan")
+			Expect(k8sClient.Create(ctx, manifest)).To(Succeed())
+			Expect(k8sClient.Create(ctx, plan)).To(Succeed())
+		})
+
+		It("Should be admitted if the manifest has not been applied", func() {
+			manifest.Spec.Components.Workloads.Helm[0].Version = "v2.8.6"
+
+			Expect(k8sClient.Update(ctx, manifest)).To(Succeed())
+		})
+
+		It("Should be denied if the manifest has been applied by a plan", func() {
+			plan.Status.LastSuccessfulReleaseVersion = "3.1.0"
+			Expect(k8sClient.Status().Update(ctx, plan)).To(Succeed())
+
+			Eventually(func() error {
+				manifest.Spec.Components.Workloads.Helm[0].Version = "v2.8.7"
+				return k8sClient.Update(ctx, manifest)
+			}).Should(MatchError(ContainSubstring("release manifest 'release-manifest-3-1-0' cannot be modified as it has been applied by upgrade plan 'plan-release-manifest'")))
+		})
+	})
+})
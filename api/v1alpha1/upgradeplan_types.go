@@ -18,20 +18,62 @@ package v1alpha1
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 
+	batchv1 "k8s.io/api/batch/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	UpgradePlanFinalizer = "upgradeplan.lifecycle.suse.com/finalizer"
 
+	// PausedAnnotation pauses the upgrade when set to "true".
+	PausedAnnotation = "lifecycle.suse.com/paused"
+
 	ValidationFailedCondition     = "ValidationFailed"
 	UnsupportedArchitectureReason = "UnsupportedArchitecture"
+	NodesNotReadyReason           = "NodesNotReady"
+	SUCPlansApplyingReason        = "SUCPlansApplying"
+	ControlPlaneUnhealthyReason   = "ControlPlaneUnhealthy"
+	DrainBlockedReason            = "DrainBlocked"
+	InsufficientDiskSpaceReason   = "InsufficientDiskSpace"
+	UnsupportedVersionSkewReason  = "UnsupportedVersionSkew"
+	UnsupportedUpgradePathReason  = "UnsupportedUpgradePath"
 
 	OperatingSystemUpgradedCondition = "OSUpgraded"
 	KubernetesUpgradedCondition      = "KubernetesUpgraded"
 
+	DryRunCondition        = "DryRun"
+	PreviewGeneratedReason = "PreviewGenerated"
+
+	PausedCondition      = "Paused"
+	PauseRequestedReason = "PauseRequested"
+
+	// MaintenanceWindowCondition is set while the start of an upgrade stage is postponed
+	// or a running node upgrade stage is suspended until the next maintenance window opens.
+	MaintenanceWindowCondition     = "WaitingForMaintenanceWindow"
+	OutsideMaintenanceWindowReason = "OutsideMaintenanceWindow"
+	MaintenanceWindowClosedReason  = "MaintenanceWindowClosed"
+
+	// CanarySoakReason identifies the wait periods spent soaking the canary worker nodes.
+	CanarySoakReason = "CanarySoak"
+
+	ReleaseManifestRetrievedCondition    = "ReleaseManifestRetrieved"
+	ReleaseManifestRetrievedReason       = "Retrieved"
+	ReleaseManifestFetchFailedReason     = "FetchFailed"
+	ReleaseManifestParseFailedReason     = "ParseFailed"
+	ReleaseManifestVersionMismatchReason = "VersionMismatch"
+	ReleaseManifestApplyFailedReason     = "ApplyFailed"
+
+	// ReleaseManifestVerificationFailedReason is reported under both the ReleaseManifestRetrieved
+	// and ValidationFailed conditions when the signature of the release manifest cannot be verified.
+	ReleaseManifestVerificationFailedReason = "ReleaseManifestVerificationFailed"
+
 	// UpgradeError indicates that the upgrade process has encountered a transient error.
 	UpgradeError = "Error"
 
@@ -57,14 +99,458 @@ type UpgradePlanSpec struct {
 	// The version format is X.Y.Z, for example "3.0.2".
 	ReleaseVersion string `json:"releaseVersion"`
 	// DisableDrain specifies whether control-plane and worker nodes drain should be disabled.
+	// Deprecated: Use Drain instead.
 	// +optional
 	DisableDrain *DisableDrain `json:"disableDrain"`
+	// Drain specifies how control-plane and worker nodes are drained before being upgraded.
+	// +optional
+	Drain *Drain `json:"drain"`
 	// Helm specifies additional values for components installed via Helm.
 	// It is only advised to use this field for values that are critical for upgrades.
 	// Standard chart value updates should be performed after
 	// the respective charts have been upgraded to the next version.
 	// +optional
 	Helm []HelmValues `json:"helm"`
+	// DryRun specifies whether the upgrade should only be previewed.
+	// When enabled, the resources which the upgrade would create or update
+	// are computed and published in the status instead of being applied.
+	// +optional
+	DryRun bool `json:"dryRun"`
+	// Components specifies which of the release components should be upgraded.
+	// All components are upgraded if unset.
+	// +optional
+	Components *ComponentSelection `json:"components"`
+	// MaintenanceWindows specifies the time windows during which upgrades are allowed to run.
+	// Once a window closes, the SUC Plans of a running OS or Kubernetes upgrade are suspended
+	// (nodes which are already being upgraded will still finish) until another window opens.
+	// Helm chart upgrades which have already been started are not interrupted,
+	// but the next stage will not begin until another window opens.
+	// Upgrades are started immediately if unset.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows"`
+	// Workers specifies how the worker nodes are upgraded.
+	// +optional
+	Workers *WorkerUpgrade `json:"workers"`
+	// NodeGroups specifies an ordered list of worker node groups which are upgraded one after the other.
+	// The OS and Kubernetes upgrades of each group only begin once the previous group has been upgraded.
+	// Node groups only apply to worker nodes: control plane nodes are always upgraded first
+	// and groups selecting them are rejected. Worker nodes which do not belong to any group are upgraded last.
+	// Nodes joining the cluster during the upgrade are assigned to the respective group.
+	// +optional
+	NodeGroups []NodeGroup `json:"nodeGroups"`
+	// FailurePolicy specifies how failed Helm chart upgrades are handled.
+	// Can be overridden for specific charts via the Helm field.
+	// Defaults to Continue.
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+	// Timeouts specifies how long the upgrade stages and nodes may take
+	// before their upgrade is considered as failed.
+	// No timeouts are enforced if unset.
+	// +optional
+	Timeouts *Timeouts `json:"timeouts,omitempty"`
+	// Preflight configures the health checks which are performed before the upgrade starts.
+	// All checks are enabled by default.
+	// +optional
+	Preflight *PreflightChecks `json:"preflight"`
+	// Verification specifies hooks which must pass after each upgrade stage
+	// (OS, Kubernetes and each Helm chart) before the stage is marked as successful.
+	// +optional
+	Verification *VerificationHooks `json:"verification"`
+	// Hooks specifies Jobs which are run before and after the upgrade stages.
+	// Hooks of the same phase are run one after the other, in the specified order.
+	// +optional
+	Hooks []StageHook `json:"hooks"`
+	// EtcdSnapshot specifies whether an on-demand etcd snapshot should be taken
+	// before the Kubernetes control plane nodes are upgraded.
+	// Requires a cluster with embedded etcd.
+	// +optional
+	EtcdSnapshot bool `json:"etcdSnapshot"`
+	// AllowUnsupportedVersionSkew permits Kubernetes upgrades which skip a minor version
+	// or downgrade the nodes. Such upgrades are not supported by Kubernetes and are rejected by default.
+	// +optional
+	AllowUnsupportedVersionSkew bool `json:"allowUnsupportedVersionSkew"`
+	// ReleaseManifestSource specifies where the release manifests are retrieved from
+	// if they are not present on the cluster. Overrides the source configured in the controller.
+	// +optional
+	ReleaseManifestSource *ReleaseManifestSource `json:"releaseManifestSource,omitempty"`
+}
+
+// ReleaseManifestVersionPlaceholder is replaced with the requested release version
+// in the locations of release manifest sources.
+const ReleaseManifestVersionPlaceholder = "{releaseVersion}"
+
+// ReleaseManifestSource specifies where release manifests are retrieved from.
+// Exactly one of the sources must be set.
+// Locations may contain the "{releaseVersion}" placeholder, which is replaced with the requested release version.
+type ReleaseManifestSource struct {
+	// OCI retrieves release manifests from container images or OCI artifacts.
+	// +optional
+	OCI *OCIReleaseManifestSource `json:"oci,omitempty"`
+	// HTTP retrieves release manifests from an HTTP(S) server.
+	// +optional
+	HTTP *HTTPReleaseManifestSource `json:"http,omitempty"`
+	// ConfigMap retrieves release manifests from a ConfigMap in the namespace of the UpgradePlan.
+	// +optional
+	ConfigMap *ConfigMapReleaseManifestSource `json:"configMap,omitempty"`
+	// Git retrieves release manifests from a Git repository.
+	// +optional
+	Git *GitReleaseManifestSource `json:"git,omitempty"`
+}
+
+type OCIReleaseManifestSource struct {
+	// Image is the repository of the release manifest images, which are tagged with the release version.
+	Image string `json:"image"`
+	// PullSecret is the name of a kubernetes.io/dockerconfigjson Secret in the namespace of the UpgradePlan
+	// holding the registry credentials.
+	// +optional
+	PullSecret string `json:"pullSecret,omitempty"`
+}
+
+type HTTPReleaseManifestSource struct {
+	// URL of the release manifest.
+	URL string `json:"url"`
+	// ChecksumURL of a file holding the SHA-256 checksum of the release manifest
+	// in the format produced by sha256sum. The checksum is not verified if unset.
+	// +optional
+	ChecksumURL string `json:"checksumURL,omitempty"`
+}
+
+type ConfigMapReleaseManifestSource struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+	// Key holding the release manifest. Defaults to "release_manifest.yaml".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+type GitReleaseManifestSource struct {
+	// Repository is the HTTP(S) URL of the Git repository.
+	Repository string `json:"repository"`
+	// Revision is the branch, tag or commit to retrieve the release manifest from.
+	// Defaults to the default branch of the repository.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// Path of the release manifest within the repository.
+	Path string `json:"path"`
+	// CredentialsSecret is the name of a kubernetes.io/basic-auth Secret in the namespace of the UpgradePlan
+	// holding the credentials for the repository.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// Validate verifies that exactly one source is specified and that its location is valid.
+func (s *ReleaseManifestSource) Validate() error {
+	specified := 0
+	for _, set := range []bool{s.OCI != nil, s.HTTP != nil, s.ConfigMap != nil, s.Git != nil} {
+		if set {
+			specified++
+		}
+	}
+
+	if specified != 1 {
+		return fmt.Errorf("exactly one release manifest source must be specified")
+	}
+
+	switch {
+	case s.OCI != nil:
+		if s.OCI.Image == "" || strings.Contains(s.OCI.Image, "://") {
+			return fmt.Errorf("'%s' is not a valid release manifest image", s.OCI.Image)
+		}
+	case s.HTTP != nil:
+		if err := validateHTTPURL(s.HTTP.URL); err != nil {
+			return fmt.Errorf("'%s' is not a valid release manifest URL: %w", s.HTTP.URL, err)
+		}
+
+		if s.HTTP.ChecksumURL != "" {
+			if err := validateHTTPURL(s.HTTP.ChecksumURL); err != nil {
+				return fmt.Errorf("'%s' is not a valid release manifest checksum URL: %w", s.HTTP.ChecksumURL, err)
+			}
+		}
+	case s.ConfigMap != nil:
+		if s.ConfigMap.Name == "" {
+			return fmt.Errorf("release manifest config map name is required")
+		}
+	case s.Git != nil:
+		if err := validateHTTPURL(s.Git.Repository); err != nil {
+			return fmt.Errorf("'%s' is not a valid release manifest repository: %w", s.Git.Repository, err)
+		}
+
+		if s.Git.Path == "" {
+			return fmt.Errorf("release manifest path within repository %s is required", s.Git.Repository)
+		}
+	}
+
+	return nil
+}
+
+func validateHTTPURL(location string) error {
+	u, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("only http and https URLs are supported")
+	}
+
+	return nil
+}
+
+// Timeouts specifies the maximum durations of the upgrade stages and node upgrades.
+// Time during which the upgrade is paused does not count towards any of them.
+type Timeouts struct {
+	// Stage specifies the maximum duration of each upgrade stage (OS, Kubernetes and each Helm chart),
+	// measured from the moment the stage starts.
+	// Time spent waiting for maintenance windows or soaking canary nodes is excluded.
+	// +optional
+	Stage *metav1.Duration `json:"stage,omitempty"`
+	// Node specifies the maximum duration of the OS and Kubernetes upgrades of a single node,
+	// measured from the moment its upgrade job is first observed.
+	// +optional
+	Node *metav1.Duration `json:"node,omitempty"`
+}
+
+type PreflightChecks struct {
+	// Disabled specifies whether all pre-flight checks should be skipped.
+	// +optional
+	Disabled bool `json:"disabled"`
+	// Skip specifies the pre-flight checks which should not be performed.
+	// +optional
+	Skip []PreflightCheck `json:"skip"`
+	// MinimumEphemeralStorage specifies the allocatable ephemeral storage
+	// which each node is required to report for the DiskSpace check.
+	// Only the DiskPressure node condition is checked if unset.
+	// +optional
+	MinimumEphemeralStorage *resource.Quantity `json:"minimumEphemeralStorage"`
+	// FailOnBlockingDisruptionBudgets specifies whether PodDisruptionBudgets which would block node drains
+	// should prevent the upgrade from starting. Such budgets are only reported with a warning event by default.
+	// +optional
+	FailOnBlockingDisruptionBudgets bool `json:"failOnBlockingDisruptionBudgets"`
+}
+
+// PreflightCheck identifies a health check performed before the upgrade starts.
+// +kubebuilder:validation:Enum=NodeHealth;SUCPlans;ControlPlaneHealth;PodDisruptionBudgets;DiskSpace
+type PreflightCheck string
+
+const (
+	// PreflightCheckNodeHealth verifies that all nodes are Ready and schedulable.
+	PreflightCheckNodeHealth PreflightCheck = "NodeHealth"
+
+	// PreflightCheckSUCPlans verifies that no SUC Plans are currently being applied.
+	PreflightCheckSUCPlans PreflightCheck = "SUCPlans"
+
+	// PreflightCheckControlPlaneHealth verifies that the etcd and control plane pods are running and ready.
+	PreflightCheckControlPlaneHealth PreflightCheck = "ControlPlaneHealth"
+
+	// PreflightCheckPodDisruptionBudgets verifies that the PodDisruptionBudgets permit draining the nodes.
+	// Only budgets covering pods which run on nodes that are going to be drained are taken into account.
+	PreflightCheckPodDisruptionBudgets PreflightCheck = "PodDisruptionBudgets"
+
+	// PreflightCheckDiskSpace verifies that the nodes report enough disk space.
+	PreflightCheckDiskSpace PreflightCheck = "DiskSpace"
+)
+
+// Enabled returns whether the specified pre-flight check should be performed.
+func (p *PreflightChecks) Enabled(check PreflightCheck) bool {
+	if p == nil {
+		return true
+	}
+
+	return !p.Disabled && !slices.Contains(p.Skip, check)
+}
+
+type VerificationHooks struct {
+	// Workloads specifies the workloads which must be fully rolled out.
+	// +optional
+	Workloads []WorkloadReference `json:"workloads"`
+	// Endpoints specifies the HTTP endpoints which must respond with a 2xx status code.
+	// +optional
+	Endpoints []HTTPEndpoint `json:"endpoints"`
+}
+
+// WorkloadKind specifies the kind of verified workload.
+// +kubebuilder:validation:Enum=Deployment;DaemonSet;StatefulSet
+type WorkloadKind string
+
+const (
+	WorkloadKindDeployment  WorkloadKind = "Deployment"
+	WorkloadKindDaemonSet   WorkloadKind = "DaemonSet"
+	WorkloadKindStatefulSet WorkloadKind = "StatefulSet"
+)
+
+type WorkloadReference struct {
+	Kind      WorkloadKind `json:"kind"`
+	Name      string       `json:"name"`
+	Namespace string       `json:"namespace"`
+}
+
+type HTTPEndpoint struct {
+	// URL specifies the http or https address of the endpoint.
+	URL string `json:"url"`
+	// InsecureSkipTLSVerify specifies whether the server certificate should not be verified.
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify"`
+}
+
+type StageHook struct {
+	// Name identifies the hook and is used in the names of its Jobs.
+	Name string `json:"name"`
+	// Phase specifies whether the hook is run before or after the stage.
+	Phase HookPhase `json:"phase"`
+	// Stages limits the hook to the specified stages, which are
+	// "OS", "Kubernetes" or the pretty names of the Helm charts.
+	// The hook is run for every stage if unset.
+	// +optional
+	Stages []string `json:"stages"`
+	// FailurePolicy specifies how the stage proceeds if the hook Job fails.
+	// Defaults to Fail.
+	// +optional
+	FailurePolicy HookFailurePolicy `json:"failurePolicy,omitempty"`
+	// Template specifies the Job which is created in the namespace of the upgrade plan for each stage.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Template batchv1.JobTemplateSpec `json:"template"`
+}
+
+// RunsFor returns whether the hook should be run for the specified stage.
+func (h *StageHook) RunsFor(stage string) bool {
+	return len(h.Stages) == 0 || slices.Contains(h.Stages, stage)
+}
+
+// HookPhase specifies when a hook is run.
+// +kubebuilder:validation:Enum=Pre;Post
+type HookPhase string
+
+const (
+	// HookPhasePre runs the hook before the stage starts.
+	HookPhasePre HookPhase = "Pre"
+
+	// HookPhasePost runs the hook once the stage has completed,
+	// before the stage is marked as successful.
+	HookPhasePost HookPhase = "Post"
+)
+
+// HookFailurePolicy specifies how a stage proceeds when its hook fails.
+// +kubebuilder:validation:Enum=Fail;Continue
+type HookFailurePolicy string
+
+const (
+	// HookFailurePolicyFail marks the stage as failed.
+	HookFailurePolicyFail HookFailurePolicy = "Fail"
+
+	// HookFailurePolicyContinue reports the failure and proceeds with the stage.
+	HookFailurePolicyContinue HookFailurePolicy = "Continue"
+)
+
+// FailurePolicy specifies how the upgrade proceeds when a Helm chart fails to upgrade.
+// +kubebuilder:validation:Enum=Continue;Halt;Rollback
+type FailurePolicy string
+
+const (
+	// FailurePolicyContinue marks the chart upgrade as failed
+	// and proceeds with the upgrade of the remaining charts.
+	FailurePolicyContinue FailurePolicy = "Continue"
+
+	// FailurePolicyHalt marks the chart upgrade as failed
+	// and stops the upgrade of the remaining charts.
+	FailurePolicyHalt FailurePolicy = "Halt"
+
+	// FailurePolicyRollback rolls the chart back to its previously installed release
+	// before proceeding with the upgrade of the remaining charts.
+	FailurePolicyRollback FailurePolicy = "Rollback"
+)
+
+type NodeGroup struct {
+	// Name identifies the group. Must be a lowercase alphanumeric string (dashes are allowed)
+	// of at most 16 characters, as it is used for naming the SUC Plans of the group.
+	Name string `json:"name"`
+	// NodeSelector selects the worker nodes which are part of the group.
+	// Nodes matching multiple groups only belong to the first of them.
+	// Must not select control plane nodes. The selected nodes are required to have the kubernetes.io/hostname label.
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector"`
+}
+
+type WorkerUpgrade struct {
+	// Concurrency specifies the number of worker nodes which are upgraded at the same time.
+	// Either an absolute number (e.g. 5) or a percentage of the worker nodes (e.g. "10%").
+	// Percentages are rounded up. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	Concurrency *intstr.IntOrString `json:"concurrency"`
+	// Canary specifies a batch of worker nodes which is upgraded
+	// before the rest of the worker nodes are targeted.
+	// +optional
+	Canary *CanaryBatch `json:"canary"`
+}
+
+type CanaryBatch struct {
+	// NodeSelector selects the worker nodes which are part of the canary batch.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector"`
+	// Count specifies the number of worker nodes which are part of the canary batch.
+	// Ignored if NodeSelector is specified.
+	// +optional
+	Count int `json:"count"`
+	// SoakDuration specifies how long the canary nodes must stay healthy
+	// after being upgraded before the rest of the worker nodes are targeted.
+	// +optional
+	SoakDuration metav1.Duration `json:"soakDuration"`
+}
+
+type MaintenanceWindow struct {
+	// Schedule specifies when the window opens in standard cron format, e.g. "0 22 * * *".
+	Schedule string `json:"schedule"`
+	// Duration specifies how long the window stays open, e.g. "4h".
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone specifies the IANA time zone the schedule is evaluated in, e.g. "Europe/Berlin".
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone"`
+}
+
+type ComponentSelection struct {
+	// SkipOS specifies whether the operating system upgrade should be skipped.
+	// +optional
+	SkipOS bool `json:"skipOS"`
+	// SkipKubernetes specifies whether the Kubernetes upgrade should be skipped.
+	// +optional
+	SkipKubernetes bool `json:"skipKubernetes"`
+	// Charts limits the upgrade of additional components to the specified charts only.
+	// Charts are matched by either their pretty name or release name.
+	// +optional
+	Charts []string `json:"charts"`
+	// ExcludeCharts specifies charts which should not be upgraded.
+	// Charts are matched by either their pretty name or release name.
+	// Cannot be used together with Charts.
+	// +optional
+	ExcludeCharts []string `json:"excludeCharts"`
+}
+
+// IncludesOS returns whether the operating system should be upgraded.
+func (c *ComponentSelection) IncludesOS() bool {
+	return c == nil || !c.SkipOS
+}
+
+// IncludesKubernetes returns whether Kubernetes should be upgraded.
+func (c *ComponentSelection) IncludesKubernetes() bool {
+	return c == nil || !c.SkipKubernetes
+}
+
+// IncludesChart returns whether the specified chart should be upgraded.
+func (c *ComponentSelection) IncludesChart(chart *HelmChart) bool {
+	if c == nil {
+		return true
+	}
+
+	matches := func(name string) bool {
+		return name == chart.PrettyName || name == chart.ReleaseName
+	}
+
+	if len(c.Charts) != 0 {
+		return slices.ContainsFunc(c.Charts, matches)
+	}
+
+	return !slices.ContainsFunc(c.ExcludeCharts, matches)
 }
 
 type DisableDrain struct {
@@ -74,9 +560,56 @@ type DisableDrain struct {
 	Worker bool `json:"worker"`
 }
 
+type Drain struct {
+	// +optional
+	ControlPlane *DrainOptions `json:"controlPlane"`
+	// +optional
+	Worker *DrainOptions `json:"worker"`
+}
+
+type DrainOptions struct {
+	// Enabled specifies whether nodes should be drained.
+	// By default, nodes are only drained if there are other nodes
+	// of the same role which the workloads can be moved to.
+	// +optional
+	Enabled *bool `json:"enabled"`
+	// Timeout specifies how long to wait for the drain to finish. Defaults to 15m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout"`
+	// GracePeriod specifies the time given to each pod to terminate gracefully.
+	// Defaults to the termination grace period of the pods.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod"`
+	// PodSelector limits the drain to the pods matching the selector.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector"`
+	// SkipWaitForDeleteTimeout specifies to skip waiting for pods
+	// whose deletion timestamp is older than the given duration.
+	// +optional
+	SkipWaitForDeleteTimeout *metav1.Duration `json:"skipWaitForDeleteTimeout"`
+	// Force specifies whether pods not managed by a controller should be deleted. Defaults to true.
+	// +optional
+	Force *bool `json:"force"`
+	// DisableEviction specifies whether pods should be deleted instead of evicted,
+	// bypassing PodDisruptionBudget checks.
+	// +optional
+	DisableEviction bool `json:"disableEviction"`
+	// DeleteEmptydirData specifies whether pods using emptyDir volumes should be deleted. Defaults to true.
+	// +optional
+	DeleteEmptydirData *bool `json:"deleteEmptydirData"`
+	// IgnoreDaemonSets specifies whether DaemonSet-managed pods should be ignored. Defaults to true.
+	// +optional
+	IgnoreDaemonSets *bool `json:"ignoreDaemonSets"`
+}
+
 type HelmValues struct {
-	Chart  string                `json:"chart"`
+	Chart string `json:"chart"`
+	// +optional
 	Values *apiextensionsv1.JSON `json:"values"`
+	// FailurePolicy overrides the failure policy of the upgrade plan for this chart,
+	// including its dependency and add-on charts.
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
 }
 
 // UpgradePlanStatus defines the observed state of UpgradePlan
@@ -97,10 +630,330 @@ type UpgradePlanStatus struct {
 
 	// LastSuccessfulReleaseVersion is the last release version that this UpgradePlan has successfully upgraded to.
 	LastSuccessfulReleaseVersion string `json:"lastSuccessfulReleaseVersion,omitempty"`
+
+	// Preview contains the changes which the upgrade would apply to the cluster.
+	// Only populated when DryRun is enabled.
+	Preview *UpgradePreview `json:"preview,omitempty"`
+
+	// NodeGroups contains the upgrade progress of the node groups specified in the UpgradePlan.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	NodeGroups []NodeGroupStatus `json:"nodeGroups,omitempty"`
+
+	// Phase summarizes the current state of the upgrade.
+	// +optional
+	Phase UpgradePhase `json:"phase,omitempty"`
+
+	// StartedAt is the time at which the upgrade of the current generation started.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is the time at which the upgrade of the current generation
+	// has either succeeded or failed.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// Progress describes how far the upgrade has advanced.
+	// +optional
+	Progress *UpgradeProgress `json:"progress,omitempty"`
+
+	// Nodes contains the upgrade state of each cluster node
+	// during the current (or last) node upgrade stage.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Nodes []NodeStatus `json:"nodes,omitempty"`
+
+	// EtcdSnapshot describes the etcd snapshot taken before the Kubernetes upgrade.
+	// +optional
+	EtcdSnapshot *EtcdSnapshotStatus `json:"etcdSnapshot,omitempty"`
+
+	// Hops contains the intermediate releases the cluster is upgraded through, ending with the target release.
+	// Only populated when the target release does not support upgrades from the last successful release version.
+	// +optional
+	Hops []ReleaseHop `json:"hops,omitempty"`
+
+	// PausePeriods records when the upgrade has been paused and resumed.
+	// Time spent paused is excluded from the upgrade timeouts.
+	// +optional
+	PausePeriods []PausePeriod `json:"pausePeriods,omitempty"`
+
+	// WaitPeriods records when the upgrade has been waiting for a maintenance window
+	// or for the canary worker nodes to soak.
+	// Time spent waiting is excluded from the upgrade timeouts.
+	// +optional
+	WaitPeriods []WaitPeriod `json:"waitPeriods,omitempty"`
+
+	// Stages records when the upgrade stages of the current release started.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Stages []StageStatus `json:"stages,omitempty"`
+
+	// ReleaseManifestRetrieval tracks the failed attempts to retrieve the manifest of the release being upgraded to.
+	// Meant for internal use only.
+	// +optional
+	ReleaseManifestRetrieval *ReleaseManifestRetrieval `json:"releaseManifestRetrieval,omitempty"`
+}
+
+// ReleaseManifestRetrieval describes the failed attempts to retrieve a release manifest.
+type ReleaseManifestRetrieval struct {
+	ReleaseVersion string `json:"releaseVersion"`
+	// ObservedGeneration is the UpgradePlan generation for which the attempts were made.
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Attempts is the number of failed attempts.
+	Attempts int32 `json:"attempts"`
+	// LastAttemptTime is the time of the last failed attempt.
+	// +optional
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+}
+
+// PausePeriod describes a period during which the upgrade has been paused.
+type PausePeriod struct {
+	// PausedAt is the time at which the upgrade was paused.
+	PausedAt metav1.Time `json:"pausedAt"`
+	// ResumedAt is the time at which the upgrade was resumed.
+	// Unset while the upgrade is paused.
+	// +optional
+	ResumedAt *metav1.Time `json:"resumedAt,omitempty"`
+}
+
+// WaitPeriod describes a period during which the upgrade has been waiting.
+type WaitPeriod struct {
+	// Reason is either OutsideMaintenanceWindow or CanarySoak.
+	Reason string `json:"reason"`
+	// StartedAt is the time at which the wait started.
+	StartedAt metav1.Time `json:"startedAt"`
+	// EndedAt is the time at which the wait ended.
+	// Unset while the upgrade is waiting.
+	// +optional
+	EndedAt *metav1.Time `json:"endedAt,omitempty"`
+}
+
+// StageStatus describes when an upgrade stage started.
+type StageStatus struct {
+	// Type is the type of the condition tracking the stage (e.g. "OSUpgraded").
+	Type string `json:"type"`
+	// StartedAt is the time at which the stage was first observed in progress.
+	// Unlike the last transition time of the stage condition, it is not reset by transient errors.
+	StartedAt metav1.Time `json:"startedAt"`
+}
+
+// ReleaseHop describes the upgrade to a single release of a multi-hop upgrade.
+type ReleaseHop struct {
+	ReleaseVersion string `json:"releaseVersion"`
+	// StartedAt is the time at which the upgrade to the release started.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// CompletedAt is the time at which the upgrade to the release succeeded.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// EtcdSnapshotStatus describes an on-demand etcd snapshot.
+type EtcdSnapshotStatus struct {
+	// Name is the name of the snapshot.
+	// The snapshot files are stored as "<name>-<node>-<timestamp>".
+	Name string `json:"name"`
+	// Node is the control plane node which has taken the snapshot.
+	Node string `json:"node"`
+	// CompletedAt is the time at which the snapshot was first observed as completed.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// NodeStatus describes the upgrade state of a single node.
+type NodeStatus struct {
+	Name string `json:"name"`
+	// Role is either "control-plane" or "worker".
+	Role string `json:"role"`
+	// OSImage is the OS image currently reported by the node.
+	// +optional
+	OSImage string `json:"osImage,omitempty"`
+	// KubeletVersion is the kubelet version currently reported by the node.
+	// +optional
+	KubeletVersion string `json:"kubeletVersion,omitempty"`
+	// Target is the OS image or kubelet version targeted by the current node upgrade stage.
+	// +optional
+	Target string `json:"target,omitempty"`
+	// State is one of Pending, InProgress, Succeeded or Failed.
+	State string `json:"state"`
+	// StartedAt is the time at which the upgrade of the node was first observed.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// CompletedAt is the time at which the node was first observed as upgraded.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+	// LastError is the failure message of the last failed upgrade job of the node.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// UpgradePhase summarizes the current state of the upgrade.
+// +kubebuilder:validation:Enum=Pending;OSUpgrade;KubernetesUpgrade;WorkloadUpgrade;Succeeded;Failed
+type UpgradePhase string
+
+const (
+	UpgradePhasePending           UpgradePhase = "Pending"
+	UpgradePhaseOSUpgrade         UpgradePhase = "OSUpgrade"
+	UpgradePhaseKubernetesUpgrade UpgradePhase = "KubernetesUpgrade"
+	UpgradePhaseWorkloadUpgrade   UpgradePhase = "WorkloadUpgrade"
+	UpgradePhaseSucceeded         UpgradePhase = "Succeeded"
+	UpgradePhaseFailed            UpgradePhase = "Failed"
+)
+
+// UpgradeProgress describes how far the upgrade has advanced.
+type UpgradeProgress struct {
+	// CurrentStage is the name of the component which is currently being upgraded
+	// (e.g. "OS", "Kubernetes" or the name of a Helm chart).
+	// +optional
+	CurrentStage string `json:"currentStage,omitempty"`
+	// NodesUpgraded is the number of nodes which have been upgraded during the current node upgrade stage.
+	// Equals NodesTotal once all node upgrade stages have finished.
+	NodesUpgraded int `json:"nodesUpgraded"`
+	// NodesTotal is the number of nodes in the cluster.
+	NodesTotal int `json:"nodesTotal"`
+}
+
+// NodeGroupStatus describes the upgrade progress of a node group.
+type NodeGroupStatus struct {
+	Name string `json:"name"`
+	// Nodes lists the hostnames of the nodes which are part of the group.
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	PreviewActionCreate = "Create"
+	PreviewActionUpdate = "Update"
+	PreviewActionSkip   = "Skip"
+)
+
+// UpgradePreview describes the changes which an UpgradePlan would apply to the cluster.
+type UpgradePreview struct {
+	// ObservedGeneration is the UpgradePlan generation that the preview was generated for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ReleaseVersion is the release version that the preview was generated for.
+	ReleaseVersion string `json:"releaseVersion"`
+	// GeneratedAt is the time at which the preview was last generated.
+	// The preview is refreshed periodically for as long as the dry run is enabled.
+	GeneratedAt metav1.Time `json:"generatedAt"`
+	// ValidationErrors lists the reasons why the upgrade would not start, e.g. an unsupported upgrade path.
+	// Errors specific to a single release are reported under Releases.
+	// +optional
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+	// Releases describes the upgrade to each release the cluster would be upgraded through,
+	// ending with the target release. Contains a single entry unless intermediate releases are required.
+	// +optional
+	Releases []ReleasePreview `json:"releases,omitempty"`
+}
+
+// ReleasePreview describes the changes which the upgrade to a single release would apply to the cluster.
+type ReleasePreview struct {
+	ReleaseVersion string `json:"releaseVersion"`
+	// ValidationErrors lists the validations which the upgrade to the release would fail,
+	// e.g. an unsupported Kubernetes version skew or a failed pre-flight check.
+	// Pre-flight checks reflect the current state of the cluster and are only performed for the first release.
+	// +optional
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+	// ValidationWarnings lists the pre-flight check warnings which would not prevent the upgrade from starting,
+	// e.g. PodDisruptionBudgets which would block node drains.
+	// +optional
+	ValidationWarnings []string `json:"validationWarnings,omitempty"`
+	// OperatingSystem describes the resources used for the OS upgrade.
+	// Unset if the OS upgrade is excluded.
+	// +optional
+	OperatingSystem *NodeUpgradePreview `json:"operatingSystem,omitempty"`
+	// Kubernetes describes the resources used for the Kubernetes upgrade.
+	// Unset if the Kubernetes upgrade is excluded.
+	// +optional
+	Kubernetes *NodeUpgradePreview `json:"kubernetes,omitempty"`
+	// Helm describes the HelmChart resources used for the upgrade of additional components.
+	// +optional
+	Helm []HelmChartPreview `json:"helm,omitempty"`
+}
+
+// NodeUpgradePreview describes the SUC resources used for upgrading cluster nodes.
+type NodeUpgradePreview struct {
+	// Version is the target version of the component.
+	Version string `json:"version"`
+	// Secret is the name of the Secret containing the upgrade script, if any.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+	// Plans lists the SUC Plans which would be created, in order of execution.
+	Plans []PlanPreview `json:"plans"`
+}
+
+// PlanPreview describes a single SUC Plan.
+// Names are generated with a placeholder suffix which differs from the one used during the actual upgrade.
+type PlanPreview struct {
+	Name         string                `json:"name"`
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// Nodes lists the names of the nodes currently matching the node selector.
+	// +optional
+	Nodes       []string `json:"nodes,omitempty"`
+	Concurrency int64    `json:"concurrency"`
+	// Drain describes the drain settings. Nodes are not drained if unset.
+	// +optional
+	Drain *DrainPreview `json:"drain,omitempty"`
+}
+
+type DrainPreview struct {
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+	// GracePeriod is the pod termination grace period in seconds.
+	// +optional
+	GracePeriod *int32 `json:"gracePeriod,omitempty"`
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// SkipWaitForDeleteTimeout is the timeout in seconds after which pods being deleted are no longer waited for.
+	// +optional
+	SkipWaitForDeleteTimeout int `json:"skipWaitForDeleteTimeout,omitempty"`
+	// +optional
+	Force bool `json:"force,omitempty"`
+	// +optional
+	DisableEviction bool `json:"disableEviction,omitempty"`
+	// +optional
+	DeleteEmptydirData bool `json:"deleteEmptydirData,omitempty"`
+	// +optional
+	IgnoreDaemonSets bool `json:"ignoreDaemonSets,omitempty"`
+}
+
+// HelmChartPreview describes the changes to a single HelmChart resource.
+type HelmChartPreview struct {
+	ReleaseName string `json:"releaseName"`
+	Chart       string `json:"chart"`
+	// +optional
+	Repository string `json:"repository,omitempty"`
+	// InstalledVersion is the chart version which is currently installed.
+	// +optional
+	InstalledVersion string `json:"installedVersion,omitempty"`
+	Version          string `json:"version"`
+	// Action is the operation that would be performed over the HelmChart resource.
+	// One of Create, Update or Skip.
+	Action string `json:"action"`
+	// Values contains the merged chart values in YAML format.
+	// +optional
+	Values string `json:"values,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Release",type=string,JSONPath=`.spec.releaseVersion`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Stage",type=string,JSONPath=`.status.progress.currentStage`
+// +kubebuilder:printcolumn:name="Nodes Upgraded",type=integer,JSONPath=`.status.progress.nodesUpgraded`
+// +kubebuilder:printcolumn:name="Nodes Total",type=integer,JSONPath=`.status.progress.nodesTotal`
+// +kubebuilder:printcolumn:name="Started",type=date,JSONPath=`.status.startedAt`,priority=1
+// +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.completedAt`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // UpgradePlan is the Schema for the upgradeplans API
 type UpgradePlan struct {
@@ -124,6 +977,23 @@ func init() {
 	SchemeBuilder.Register(&UpgradePlan{}, &UpgradePlanList{})
 }
 
+// HasApplied returns whether the plan has successfully upgraded the cluster to the specified release,
+// either as its target or as an intermediate release.
+func (p *UpgradePlan) HasApplied(releaseVersion string) bool {
+	if p.Status.LastSuccessfulReleaseVersion == releaseVersion {
+		return true
+	}
+
+	return slices.ContainsFunc(p.Status.Hops, func(hop ReleaseHop) bool {
+		return hop.ReleaseVersion == releaseVersion && hop.CompletedAt != nil
+	})
+}
+
+// IsPaused returns whether the upgrade plan has been paused.
+func (p *UpgradePlan) IsPaused() bool {
+	return p.Annotations[PausedAnnotation] == "true"
+}
+
 func GetChartConditionType(prettyName string) string {
 	return fmt.Sprintf("%sUpgraded", prettyName)
 }
@@ -0,0 +1,111 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestComponentSelection(t *testing.T) {
+	rancher := &HelmChart{PrettyName: "Rancher", ReleaseName: "rancher"}
+	longhorn := &HelmChart{PrettyName: "Longhorn", ReleaseName: "longhorn"}
+
+	tests := []struct {
+		name               string
+		selection          *ComponentSelection
+		expectedOS         bool
+		expectedKubernetes bool
+		expectedRancher    bool
+		expectedLonghorn   bool
+	}{
+		{
+			name:               "Unset selection",
+			expectedOS:         true,
+			expectedKubernetes: true,
+			expectedRancher:    true,
+			expectedLonghorn:   true,
+		},
+		{
+			name:               "Skip OS",
+			selection:          &ComponentSelection{SkipOS: true},
+			expectedKubernetes: true,
+			expectedRancher:    true,
+			expectedLonghorn:   true,
+		},
+		{
+			name:             "Skip OS and Kubernetes",
+			selection:        &ComponentSelection{SkipOS: true, SkipKubernetes: true},
+			expectedRancher:  true,
+			expectedLonghorn: true,
+		},
+		{
+			name:               "Include chart by pretty name",
+			selection:          &ComponentSelection{Charts: []string{"Rancher"}},
+			expectedOS:         true,
+			expectedKubernetes: true,
+			expectedRancher:    true,
+		},
+		{
+			name:               "Exclude chart by release name",
+			selection:          &ComponentSelection{ExcludeCharts: []string{"rancher"}},
+			expectedOS:         true,
+			expectedKubernetes: true,
+			expectedLonghorn:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedOS, test.selection.IncludesOS())
+			assert.Equal(t, test.expectedKubernetes, test.selection.IncludesKubernetes())
+			assert.Equal(t, test.expectedRancher, test.selection.IncludesChart(rancher))
+			assert.Equal(t, test.expectedLonghorn, test.selection.IncludesChart(longhorn))
+		})
+	}
+}
+
+func TestPreflightChecksEnabled(t *testing.T) {
+	var unset *PreflightChecks
+	assert.True(t, unset.Enabled(PreflightCheckNodeHealth))
+
+	skipped := &PreflightChecks{Skip: []PreflightCheck{PreflightCheckPodDisruptionBudgets}}
+	assert.True(t, skipped.Enabled(PreflightCheckNodeHealth))
+	assert.False(t, skipped.Enabled(PreflightCheckPodDisruptionBudgets))
+
+	disabled := &PreflightChecks{Disabled: true}
+	assert.False(t, disabled.Enabled(PreflightCheckNodeHealth))
+}
+
+func TestStageHookRunsFor(t *testing.T) {
+	hook := &StageHook{Name: "backup"}
+	assert.True(t, hook.RunsFor("OS"))
+	assert.True(t, hook.RunsFor("Rancher"))
+
+	hook.Stages = []string{"Kubernetes", "Longhorn"}
+	assert.False(t, hook.RunsFor("OS"))
+	assert.True(t, hook.RunsFor("Kubernetes"))
+	assert.True(t, hook.RunsFor("Longhorn"))
+}
+
+func TestUpgradePlan_HasApplied(t *testing.T) {
+	now := metav1.Now()
+
+	plan := &UpgradePlan{
+		Status: UpgradePlanStatus{
+			LastSuccessfulReleaseVersion: "3.1.0",
+			Hops: []ReleaseHop{
+				{ReleaseVersion: "3.1.0", StartedAt: &now, CompletedAt: &now},
+				{ReleaseVersion: "3.2.0", StartedAt: &now},
+			},
+		},
+	}
+
+	assert.True(t, plan.HasApplied("3.1.0"))
+	assert.False(t, plan.HasApplied("3.2.0"))
+	assert.False(t, plan.HasApplied("3.0.0"))
+
+	plan.Status.LastSuccessfulReleaseVersion = "3.2.0"
+	assert.True(t, plan.HasApplied("3.1.0"))
+	assert.True(t, plan.HasApplied("3.2.0"))
+}
@@ -19,20 +19,38 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"slices"
+	"strings"
+	"time"
 
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/version"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 func SetupWebhookWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewWebhookManagedBy(mgr).
-		WithValidator(&UpgradePlanValidator{}).
+	err := ctrl.NewWebhookManagedBy(mgr).
+		WithValidator(&UpgradePlanValidator{Client: mgr.GetClient()}).
 		For(&UpgradePlan{}).
 		Complete()
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		WithValidator(&ReleaseManifestValidator{Client: mgr.GetClient()}).
+		For(&ReleaseManifest{}).
+		Complete()
 }
 
 // NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
@@ -41,19 +59,71 @@ func SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 var _ webhook.CustomValidator = &UpgradePlanValidator{}
 
-type UpgradePlanValidator struct{}
+type UpgradePlanValidator struct {
+	// Client is used to look up the cluster nodes and release manifests.
+	// Cluster state dependent validations are skipped if unset.
+	Client client.Reader
+}
 
-func (*UpgradePlanValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+func (v *UpgradePlanValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	upgradePlan, ok := obj.(*UpgradePlan)
 	if !ok {
 		return nil, fmt.Errorf("unexpected object type: %T", obj)
 	}
 
-	_, err := validateReleaseVersion(upgradePlan.Spec.ReleaseVersion)
-	return nil, err
+	if _, err := validateReleaseVersion(upgradePlan.Spec.ReleaseVersion); err != nil {
+		return nil, err
+	}
+
+	if err := validateComponents(upgradePlan.Spec.Components); err != nil {
+		return nil, err
+	}
+
+	if err := validateMaintenanceWindows(upgradePlan.Spec.MaintenanceWindows); err != nil {
+		return nil, err
+	}
+
+	if err := validateWorkers(upgradePlan.Spec.Workers); err != nil {
+		return nil, err
+	}
+
+	if err := validateNodeGroups(upgradePlan.Spec.NodeGroups); err != nil {
+		return nil, err
+	}
+
+	if err := validateDrain(upgradePlan.Spec.Drain); err != nil {
+		return nil, err
+	}
+
+	if err := validateTimeouts(upgradePlan.Spec.Timeouts); err != nil {
+		return nil, err
+	}
+
+	if err := validatePreflight(upgradePlan.Spec.Preflight); err != nil {
+		return nil, err
+	}
+
+	if err := validateVerification(upgradePlan.Spec.Verification); err != nil {
+		return nil, err
+	}
+
+	if err := validateHooks(upgradePlan.Spec.Hooks); err != nil {
+		return nil, err
+	}
+
+	if err := validateReleaseManifestSource(upgradePlan.Spec.ReleaseManifestSource); err != nil {
+		return nil, err
+	}
+
+	warnings, err := v.validateRelease(ctx, upgradePlan, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(deprecationWarnings(upgradePlan), warnings...), nil
 }
 
-func (*UpgradePlanValidator) ValidateUpdate(ctx context.Context, old, new runtime.Object) (admission.Warnings, error) {
+func (v *UpgradePlanValidator) ValidateUpdate(ctx context.Context, old, new runtime.Object) (admission.Warnings, error) {
 	oldPlan, ok := old.(*UpgradePlan)
 	if !ok {
 		return nil, fmt.Errorf("unexpected object type: %T", old)
@@ -70,6 +140,11 @@ func (*UpgradePlanValidator) ValidateUpdate(ctx context.Context, old, new runtim
 		return nil, nil
 	}
 
+	// pausing or resuming the upgrade is allowed at any point in time
+	if equality.Semantic.DeepEqual(oldPlan.Spec, newPlan.Spec) && oldPlan.IsPaused() != newPlan.IsPaused() {
+		return nil, nil
+	}
+
 	disallowingUpdateStates := []string{UpgradeInProgress, UpgradePending, UpgradeError}
 
 	for _, condition := range newPlan.Status.Conditions {
@@ -83,6 +158,46 @@ func (*UpgradePlanValidator) ValidateUpdate(ctx context.Context, old, new runtim
 		return nil, err
 	}
 
+	if err = validateComponents(newPlan.Spec.Components); err != nil {
+		return nil, err
+	}
+
+	if err = validateMaintenanceWindows(newPlan.Spec.MaintenanceWindows); err != nil {
+		return nil, err
+	}
+
+	if err = validateWorkers(newPlan.Spec.Workers); err != nil {
+		return nil, err
+	}
+
+	if err = validateNodeGroups(newPlan.Spec.NodeGroups); err != nil {
+		return nil, err
+	}
+
+	if err = validateDrain(newPlan.Spec.Drain); err != nil {
+		return nil, err
+	}
+
+	if err = validateTimeouts(newPlan.Spec.Timeouts); err != nil {
+		return nil, err
+	}
+
+	if err = validatePreflight(newPlan.Spec.Preflight); err != nil {
+		return nil, err
+	}
+
+	if err = validateVerification(newPlan.Spec.Verification); err != nil {
+		return nil, err
+	}
+
+	if err = validateHooks(newPlan.Spec.Hooks); err != nil {
+		return nil, err
+	}
+
+	if err = validateReleaseManifestSource(newPlan.Spec.ReleaseManifestSource); err != nil {
+		return nil, err
+	}
+
 	if oldPlan.Status.LastSuccessfulReleaseVersion != "" {
 		indicator, err := newReleaseVersion.Compare(oldPlan.Status.LastSuccessfulReleaseVersion)
 		if err != nil {
@@ -97,13 +212,89 @@ func (*UpgradePlanValidator) ValidateUpdate(ctx context.Context, old, new runtim
 		}
 	}
 
-	return nil, nil
+	warnings := deprecationWarnings(newPlan)
+
+	if !equality.Semantic.DeepEqual(oldPlan.Spec, newPlan.Spec) {
+		releaseWarnings, err := v.validateRelease(ctx, newPlan, oldPlan.Status.LastSuccessfulReleaseVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		warnings = append(warnings, releaseWarnings...)
+	}
+
+	return warnings, nil
 }
 
 func (*UpgradePlanValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
 	return nil, nil
 }
 
+// validateRelease validates the upgrade against the compatibility metadata of the release manifests
+// and rejects upgrades which would downgrade the cluster nodes or make them skip a minor Kubernetes version.
+// The validation is deferred to the controller if the release manifest is not yet present in the cluster.
+func (v *UpgradePlanValidator) validateRelease(ctx context.Context, upgradePlan *UpgradePlan, lastReleaseVersion string) (admission.Warnings, error) {
+	if v.Client == nil {
+		return nil, nil
+	}
+
+	manifests := &ReleaseManifestList{}
+	if err := v.Client.List(ctx, manifests, client.InNamespace(upgradePlan.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing release manifests: %w", err)
+	}
+
+	i := slices.IndexFunc(manifests.Items, func(manifest ReleaseManifest) bool {
+		return manifest.Spec.ReleaseVersion == upgradePlan.Spec.ReleaseVersion
+	})
+	if i == -1 {
+		return nil, nil
+	}
+
+	release := &manifests.Items[i]
+	var warnings admission.Warnings
+
+	if release.Spec.Compatibility.IsDeprecated(release.Spec.ReleaseVersion) {
+		warnings = append(warnings, fmt.Sprintf("release %s is deprecated", release.Spec.ReleaseVersion))
+	}
+
+	if err := release.Spec.Compatibility.ValidateUpgradeFrom(lastReleaseVersion); err != nil {
+		path := UpgradePath(lastReleaseVersion, release, manifests.Items)
+		if len(path) == 0 {
+			return nil, err
+		}
+
+		warnings = append(warnings, fmt.Sprintf("release %s will be reached through releases %v", release.Spec.ReleaseVersion, path))
+
+		// The nodes are upgraded to the first intermediate release first.
+		i = slices.IndexFunc(manifests.Items, func(manifest ReleaseManifest) bool {
+			return manifest.Spec.ReleaseVersion == path[0]
+		})
+		release = &manifests.Items[i]
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := v.Client.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	if err := release.Spec.Compatibility.ValidateNodes(nodes.Items); err != nil {
+		return nil, err
+	}
+
+	if len(nodes.Items) != 0 && upgradePlan.Spec.Components.IncludesKubernetes() && !upgradePlan.Spec.AllowUnsupportedVersionSkew {
+		distribution, err := release.Spec.Components.Kubernetes.Distribution(nodes.Items[0].Status.NodeInfo.KubeletVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = distribution.ValidateVersionSkew(nodes.Items); err != nil {
+			return nil, fmt.Errorf("%w; set 'allowUnsupportedVersionSkew' to upgrade regardless", err)
+		}
+	}
+
+	return warnings, nil
+}
+
 func validateReleaseVersion(releaseVersion string) (*version.Version, error) {
 	if releaseVersion == "" {
 		return nil, fmt.Errorf("release version is required")
@@ -116,3 +307,239 @@ func validateReleaseVersion(releaseVersion string) (*version.Version, error) {
 
 	return v, nil
 }
+
+func validateComponents(components *ComponentSelection) error {
+	if components == nil {
+		return nil
+	}
+
+	if len(components.Charts) != 0 && len(components.ExcludeCharts) != 0 {
+		return fmt.Errorf("'charts' and 'excludeCharts' cannot be specified at the same time")
+	}
+
+	return nil
+}
+
+func validateMaintenanceWindows(windows []MaintenanceWindow) error {
+	for _, window := range windows {
+		if _, err := cron.ParseStandard(window.Schedule); err != nil {
+			return fmt.Errorf("'%s' is not a valid maintenance window schedule: %w", window.Schedule, err)
+		}
+
+		if window.Duration.Duration <= 0 {
+			return fmt.Errorf("maintenance window duration must be positive")
+		}
+
+		if _, err := time.LoadLocation(window.TimeZone); err != nil {
+			return fmt.Errorf("'%s' is not a valid maintenance window time zone", window.TimeZone)
+		}
+	}
+
+	return nil
+}
+
+func validateWorkers(workers *WorkerUpgrade) error {
+	if workers == nil {
+		return nil
+	}
+
+	if workers.Concurrency != nil {
+		// Scaling against 100 nodes validates both absolute numbers and percentages.
+		concurrency, err := intstr.GetScaledValueFromIntOrPercent(workers.Concurrency, 100, true)
+		if err != nil {
+			return fmt.Errorf("invalid worker concurrency: %w", err)
+		}
+
+		if concurrency <= 0 {
+			return fmt.Errorf("worker concurrency must be positive")
+		}
+	}
+
+	if canary := workers.Canary; canary != nil {
+		if canary.NodeSelector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(canary.NodeSelector); err != nil {
+				return fmt.Errorf("invalid canary node selector: %w", err)
+			}
+		} else if canary.Count <= 0 {
+			return fmt.Errorf("canary batch requires either a node selector or a positive count")
+		}
+
+		if canary.SoakDuration.Duration < 0 {
+			return fmt.Errorf("canary soak duration must not be negative")
+		}
+	}
+
+	return nil
+}
+
+func validateNodeGroups(groups []NodeGroup) error {
+	const maxNameLength = 16
+
+	var names []string
+
+	for _, group := range groups {
+		if errs := validation.IsDNS1123Label(group.Name); len(errs) != 0 || len(group.Name) > maxNameLength {
+			return fmt.Errorf("'%s' is not a valid node group name, must be a lowercase alphanumeric string of at most %d characters", group.Name, maxNameLength)
+		}
+
+		if group.Name == "canary" {
+			return fmt.Errorf("node group name 'canary' is reserved")
+		}
+
+		if slices.Contains(names, group.Name) {
+			return fmt.Errorf("node group '%s' is specified more than once", group.Name)
+		}
+		names = append(names, group.Name)
+
+		if group.NodeSelector == nil {
+			return fmt.Errorf("node group '%s' requires a node selector", group.Name)
+		}
+
+		if _, err := metav1.LabelSelectorAsSelector(group.NodeSelector); err != nil {
+			return fmt.Errorf("invalid node selector of group '%s': %w", group.Name, err)
+		}
+
+		if selectsControlPlaneNodes(group.NodeSelector) {
+			return fmt.Errorf("node group '%s' selects control plane nodes, node groups may only contain worker nodes", group.Name)
+		}
+	}
+
+	return nil
+}
+
+// selectsControlPlaneNodes reports whether the selector explicitly targets control plane nodes.
+func selectsControlPlaneNodes(selector *metav1.LabelSelector) bool {
+	const controlPlaneLabel = "node-role.kubernetes.io/control-plane"
+
+	if _, ok := selector.MatchLabels[controlPlaneLabel]; ok {
+		return true
+	}
+
+	return slices.ContainsFunc(selector.MatchExpressions, func(requirement metav1.LabelSelectorRequirement) bool {
+		if requirement.Key != controlPlaneLabel {
+			return false
+		}
+
+		return requirement.Operator == metav1.LabelSelectorOpExists ||
+			(requirement.Operator == metav1.LabelSelectorOpIn && slices.Contains(requirement.Values, "true"))
+	})
+}
+
+func validateDrain(drain *Drain) error {
+	if drain == nil {
+		return nil
+	}
+
+	for role, options := range map[string]*DrainOptions{"control plane": drain.ControlPlane, "worker": drain.Worker} {
+		if options == nil {
+			continue
+		}
+
+		for _, duration := range []*metav1.Duration{options.Timeout, options.GracePeriod, options.SkipWaitForDeleteTimeout} {
+			if duration != nil && duration.Duration < 0 {
+				return fmt.Errorf("%s drain durations must not be negative", role)
+			}
+		}
+
+		if _, err := metav1.LabelSelectorAsSelector(options.PodSelector); err != nil {
+			return fmt.Errorf("invalid %s drain pod selector: %w", role, err)
+		}
+	}
+
+	return nil
+}
+
+func validateTimeouts(timeouts *Timeouts) error {
+	if timeouts == nil {
+		return nil
+	}
+
+	if timeouts.Stage != nil && timeouts.Stage.Duration <= 0 {
+		return fmt.Errorf("stage timeout must be positive")
+	}
+
+	if timeouts.Node != nil && timeouts.Node.Duration <= 0 {
+		return fmt.Errorf("node timeout must be positive")
+	}
+
+	return nil
+}
+
+func validatePreflight(preflight *PreflightChecks) error {
+	if preflight == nil {
+		return nil
+	}
+
+	if preflight.MinimumEphemeralStorage != nil && preflight.MinimumEphemeralStorage.Sign() < 0 {
+		return fmt.Errorf("minimum ephemeral storage must not be negative")
+	}
+
+	return nil
+}
+
+func validateVerification(verification *VerificationHooks) error {
+	if verification == nil {
+		return nil
+	}
+
+	for _, workload := range verification.Workloads {
+		if workload.Name == "" || workload.Namespace == "" {
+			return fmt.Errorf("name and namespace are required for verified %s workloads", workload.Kind)
+		}
+	}
+
+	for _, endpoint := range verification.Endpoints {
+		u, err := url.Parse(endpoint.URL)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid verification endpoint: %w", endpoint.URL, err)
+		}
+
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("'%s' is not a valid verification endpoint: only http and https URLs are supported", endpoint.URL)
+		}
+	}
+
+	return nil
+}
+
+func validateHooks(hooks []StageHook) error {
+	const maxNameLength = 16
+
+	var names []string
+
+	for _, hook := range hooks {
+		if errs := validation.IsDNS1123Label(hook.Name); len(errs) != 0 || len(hook.Name) > maxNameLength {
+			return fmt.Errorf("'%s' is not a valid hook name, must be a lowercase alphanumeric string of at most %d characters", hook.Name, maxNameLength)
+		}
+
+		id := fmt.Sprintf("%s/%s", hook.Phase, hook.Name)
+		if slices.Contains(names, id) {
+			return fmt.Errorf("%s hook '%s' is specified more than once", strings.ToLower(string(hook.Phase)), hook.Name)
+		}
+		names = append(names, id)
+
+		if len(hook.Template.Spec.Template.Spec.Containers) == 0 {
+			return fmt.Errorf("job template of hook '%s' must specify at least one container", hook.Name)
+		}
+	}
+
+	return nil
+}
+
+func validateReleaseManifestSource(source *ReleaseManifestSource) error {
+	if source == nil {
+		return nil
+	}
+
+	return source.Validate()
+}
+
+func deprecationWarnings(plan *UpgradePlan) admission.Warnings {
+	var warnings admission.Warnings
+
+	if plan.Spec.DisableDrain != nil {
+		warnings = append(warnings, "spec.disableDrain is deprecated, use spec.drain instead")
+	}
+
+	return warnings
+}
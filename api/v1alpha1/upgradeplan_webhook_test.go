@@ -17,10 +17,15 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -54,6 +59,182 @@ var _ = Describe("UpgradePlan Webhook", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(ContainSubstring("'v1' is not a semantic version")))
 		})
+
+		It("Should be denied if both included and excluded charts are specified", func() {
+			plan := &UpgradePlan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "plan1",
+					Namespace: "default",
+				},
+				Spec: UpgradePlanSpec{
+					ReleaseVersion: "3.1.0",
+					Components: &ComponentSelection{
+						Charts:        []string{"rancher"},
+						ExcludeCharts: []string{"longhorn"},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("'charts' and 'excludeCharts' cannot be specified at the same time")))
+		})
+
+		It("Should be denied if maintenance window schedule is invalid", func() {
+			plan := &UpgradePlan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "plan1",
+					Namespace: "default",
+				},
+				Spec: UpgradePlanSpec{
+					ReleaseVersion: "3.1.0",
+					MaintenanceWindows: []MaintenanceWindow{
+						{
+							Schedule: "every night",
+							Duration: metav1.Duration{Duration: time.Hour},
+						},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("'every night' is not a valid maintenance window schedule")))
+		})
+
+		It("Should be denied if a timeout is not positive", func() {
+			plan := &UpgradePlan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "plan1",
+					Namespace: "default",
+				},
+				Spec: UpgradePlanSpec{
+					ReleaseVersion: "3.1.0",
+					Timeouts: &Timeouts{
+						Node: &metav1.Duration{},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("node timeout must be positive")))
+		})
+
+		It("Should be denied if a node group selects control plane nodes", func() {
+			plan := &UpgradePlan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "plan1",
+					Namespace: "default",
+				},
+				Spec: UpgradePlanSpec{
+					ReleaseVersion: "3.1.0",
+					NodeGroups: []NodeGroup{
+						{
+							Name: "control-plane",
+							NodeSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"node-role.kubernetes.io/control-plane": "true"},
+							},
+						},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("node group 'control-plane' selects control plane nodes")))
+		})
+
+		It("Should be denied if the minimum ephemeral storage is negative", func() {
+			minimumStorage := resource.MustParse("-1Gi")
+			plan := &UpgradePlan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "plan1",
+					Namespace: "default",
+				},
+				Spec: UpgradePlanSpec{
+					ReleaseVersion: "3.1.0",
+					Preflight: &PreflightChecks{
+						MinimumEphemeralStorage: &minimumStorage,
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("minimum ephemeral storage must not be negative")))
+		})
+
+		It("Should be denied if a verification endpoint is not an http URL", func() {
+			plan := &UpgradePlan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "plan1",
+					Namespace: "default",
+				},
+				Spec: UpgradePlanSpec{
+					ReleaseVersion: "3.1.0",
+					Verification: &VerificationHooks{
+						Endpoints: []HTTPEndpoint{{URL: "tcp://rancher.cattle-system:443"}},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("only http and https URLs are supported")))
+		})
+
+		It("Should be denied if a hook is specified more than once", func() {
+			hook := StageHook{
+				Name:  "backup",
+				Phase: HookPhasePre,
+				Template: batchv1.JobTemplateSpec{
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers:    []corev1.Container{{Name: "backup", Image: "busybox"}},
+								RestartPolicy: corev1.RestartPolicyNever,
+							},
+						},
+					},
+				},
+			}
+
+			plan := &UpgradePlan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "plan1",
+					Namespace: "default",
+				},
+				Spec: UpgradePlanSpec{
+					ReleaseVersion: "3.1.0",
+					Hooks:          []StageHook{hook, hook},
+				},
+			}
+
+			err := k8sClient.Create(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("pre hook 'backup' is specified more than once")))
+		})
+
+		It("Should be denied if more than one release manifest source is specified", func() {
+			plan := &UpgradePlan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "plan1",
+					Namespace: "default",
+				},
+				Spec: UpgradePlanSpec{
+					ReleaseVersion: "3.1.0",
+					ReleaseManifestSource: &ReleaseManifestSource{
+						OCI:       &OCIReleaseManifestSource{Image: "registry.suse.com/edge/release-manifest"},
+						ConfigMap: &ConfigMapReleaseManifestSource{Name: "release-manifests"},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("exactly one release manifest source must be specified")))
+		})
 	})
 
 	Context("When updating UpgradePlan under Validating Webhook", Ordered, func() {
@@ -111,6 +292,36 @@ var _ = Describe("UpgradePlan Webhook", func() {
 			Expect(err).To(MatchError(ContainSubstring("upgrade plan cannot be edited while condition 'KubernetesUpgraded' is in 'Error' state")))
 		})
 
+		It("Should pass when the upgrade is paused or resumed while in progress", func() {
+			condition := metav1.Condition{Type: KubernetesUpgradedCondition, Status: metav1.ConditionFalse, Reason: UpgradeInProgress}
+
+			meta.SetStatusCondition(&plan.Status.Conditions, condition)
+			Expect(k8sClient.Status().Update(ctx, plan)).To(Succeed())
+
+			plan.Annotations = map[string]string{PausedAnnotation: "true"}
+			Expect(k8sClient.Update(ctx, plan)).To(Succeed())
+
+			delete(plan.Annotations, PausedAnnotation)
+			Expect(k8sClient.Update(ctx, plan)).To(Succeed())
+		})
+
+		It("Should be denied when the upgrade is paused while in progress alongside other changes", func() {
+			condition := metav1.Condition{Type: KubernetesUpgradedCondition, Status: metav1.ConditionFalse, Reason: UpgradeInProgress}
+
+			meta.SetStatusCondition(&plan.Status.Conditions, condition)
+			Expect(k8sClient.Status().Update(ctx, plan)).To(Succeed())
+
+			plan.Annotations = map[string]string{PausedAnnotation: "true"}
+			plan.Spec.ReleaseVersion = "3.1.1"
+
+			err := k8sClient.Update(ctx, plan)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring("upgrade plan cannot be edited while condition 'KubernetesUpgraded' is in 'InProgress' state")))
+
+			plan.Annotations = nil
+			plan.Spec.ReleaseVersion = "3.1.0"
+		})
+
 		It("Should be denied if release version is not specified", func() {
 			plan.Spec.ReleaseVersion = ""
 
@@ -18,18 +18,25 @@ package main
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -40,6 +47,8 @@ import (
 
 	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
 	"github.com/suse-edge/upgrade-controller/internal/controller"
+	"github.com/suse-edge/upgrade-controller/internal/registry"
+	"github.com/suse-edge/upgrade-controller/internal/source"
 	"github.com/suse-edge/upgrade-controller/internal/upgrade"
 	// +kubebuilder:scaffold:imports
 )
@@ -58,11 +67,7 @@ func init() {
 	// +kubebuilder:scaffold:scheme
 }
 
-const (
-	defaultReleaseManifestImage = "registry.opensuse.org/isv/suse/edge/lifecycle/containerfile/release-manifest"
-	defaultKubectlImage         = "registry.opensuse.org/isv/suse/edge/lifecycle/containerfile/kubectl"
-	defaultKubectlVersion       = "1.30.3"
-)
+const defaultReleaseManifestImage = "registry.opensuse.org/isv/suse/edge/lifecycle/containerfile/release-manifest"
 
 func main() {
 	var metricsAddr string
@@ -72,9 +77,12 @@ func main() {
 	var enableHTTP2 bool
 	var watchNamespace string
 	var releaseManifestImage string
-	var kubectlImage string
-	var kubectlVersion string
-	var serviceAccountName string
+	var releaseManifestPullSecret string
+	var releaseManifestSource string
+	var releaseManifestPublicKeys string
+	var releaseManifestPublicKeysSecret string
+	var registryCAFile string
+	var registryMirrors string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metric endpoint binds to. "+
 		"Use the port :8080. If not set, it will be 0 in order to disable the metrics server")
@@ -90,12 +98,22 @@ func main() {
 		"Namespace that the controller watches to reconcile resources.")
 	flag.StringVar(&releaseManifestImage, "release-manifest-image", os.Getenv("RELEASE_MANIFEST_IMAGE"),
 		"Source of release manifest container images")
-	flag.StringVar(&kubectlImage, "kubectl-image", os.Getenv("KUBECTL_IMAGE"),
-		"Source of the kubectl container image")
-	flag.StringVar(&kubectlVersion, "kubectl-version", os.Getenv("KUBECTL_VERSION"),
-		"Version of the kubectl container image")
-	flag.StringVar(&serviceAccountName, "service-account-name", os.Getenv("SERVICE_ACCOUNT_NAME"),
-		"Service account of the controller")
+	flag.StringVar(&releaseManifestPullSecret, "release-manifest-pull-secret", os.Getenv("RELEASE_MANIFEST_PULL_SECRET"),
+		"Name of the docker config Secret used to pull release manifest images, "+
+			"located in the namespace of the UpgradePlan")
+	flag.StringVar(&releaseManifestSource, "release-manifest-source", os.Getenv("RELEASE_MANIFEST_SOURCE"),
+		"Location of the release manifests, one of oci://<image>, http(s)://<url>, configmap:<name>[/<key>], "+
+			"git+http(s)://<repository>//<path>[?ref=<revision>] or file://<path>. "+
+			"Defaults to the release manifest image")
+	flag.StringVar(&releaseManifestPublicKeys, "release-manifest-public-keys", os.Getenv("RELEASE_MANIFEST_PUBLIC_KEYS"),
+		"Path to PEM encoded public keys which retrieved release manifests must be signed with")
+	flag.StringVar(&releaseManifestPublicKeysSecret, "release-manifest-public-keys-secret", os.Getenv("RELEASE_MANIFEST_PUBLIC_KEYS_SECRET"),
+		"Secret holding PEM encoded public keys which retrieved release manifests must be signed with, "+
+			"in the format <namespace>/<name>")
+	flag.StringVar(&registryCAFile, "registry-ca-file", os.Getenv("REGISTRY_CA_FILE"),
+		"Path to a PEM encoded CA bundle trusted in addition to the system CAs when pulling from registries")
+	flag.StringVar(&registryMirrors, "registry-mirrors", os.Getenv("REGISTRY_MIRRORS"),
+		"Registry mirrors in the format <registry>=<mirror>[,<registry>=<mirror>...]")
 
 	opts := zap.Options{
 		Development: true,
@@ -137,11 +155,17 @@ func main() {
 	if releaseManifestImage == "" {
 		releaseManifestImage = defaultReleaseManifestImage
 	}
-	if kubectlImage == "" {
-		kubectlImage = defaultKubectlImage
+
+	rootCAs, err := loadRootCAs(registryCAFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load CA bundle")
+		os.Exit(1)
 	}
-	if kubectlVersion == "" {
-		kubectlVersion = defaultKubectlVersion
+
+	mirrors, err := registry.ParseMirrors(registryMirrors)
+	if err != nil {
+		setupLog.Error(err, "unable to parse registry mirrors")
+		os.Exit(1)
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
@@ -175,16 +199,38 @@ func main() {
 		os.Exit(1)
 	}
 
+	sourceOptions := source.Options{
+		Reader:     mgr.GetClient(),
+		Registry:   registry.NewClient(rootCAs, mirrors),
+		HTTPClient: newHTTPClient(rootCAs),
+	}
+
+	var defaultSource source.Source = &source.OCI{
+		Image:      releaseManifestImage,
+		PullSecret: releaseManifestPullSecret,
+		Registry:   sourceOptions.Registry,
+		Reader:     sourceOptions.Reader,
+	}
+	if releaseManifestSource != "" {
+		if defaultSource, err = source.Parse(releaseManifestSource, sourceOptions); err != nil {
+			setupLog.Error(err, "unable to configure release manifest source")
+			os.Exit(1)
+		}
+	}
+
+	verifier, err := newReleaseManifestVerifier(releaseManifestPublicKeys, releaseManifestPublicKeysSecret, mgr.GetAPIReader())
+	if err != nil {
+		setupLog.Error(err, "unable to configure release manifest verification")
+		os.Exit(1)
+	}
+
 	if err = (&controller.UpgradePlanReconciler{
-		Client:               mgr.GetClient(),
-		Scheme:               mgr.GetScheme(),
-		Recorder:             mgr.GetEventRecorderFor("upgrade-plan-controller"),
-		ServiceAccount:       serviceAccountName,
-		ReleaseManifestImage: releaseManifestImage,
-		Kubectl: upgrade.ContainerImage{
-			Name:    kubectlImage,
-			Version: kubectlVersion,
-		},
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("upgrade-plan-controller"),
+		ReleaseManifestSource:   defaultSource,
+		SourceOptions:           sourceOptions,
+		ReleaseManifestVerifier: verifier,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "UpgradePlan")
 		os.Exit(1)
@@ -212,3 +258,68 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newReleaseManifestVerifier returns a verifier trusting the specified public keys,
+// or nil if neither a keys file nor a keys secret are specified.
+func newReleaseManifestVerifier(keysFile, keysSecret string, reader client.Reader) (*source.Verifier, error) {
+	if keysFile == "" && keysSecret == "" {
+		return nil, nil
+	}
+
+	verifier := &source.Verifier{Reader: reader}
+
+	if keysFile != "" {
+		data, err := os.ReadFile(keysFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading public keys: %w", err)
+		}
+
+		if verifier.Keys, err = source.ParsePublicKeys(data); err != nil {
+			return nil, fmt.Errorf("parsing public keys %s: %w", keysFile, err)
+		}
+	}
+
+	if keysSecret != "" {
+		namespace, name, found := strings.Cut(keysSecret, "/")
+		if !found || namespace == "" || name == "" {
+			return nil, fmt.Errorf("invalid public keys secret %q, expected <namespace>/<name>", keysSecret)
+		}
+
+		verifier.KeysSecret = &types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	return verifier, nil
+}
+
+// loadRootCAs returns the system CAs extended with the specified CA bundle, or nil if no bundle is specified.
+func loadRootCAs(caFile string) (*x509.CertPool, error) {
+	if caFile == "" {
+		return nil, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, fmt.Errorf("loading system CAs: %w", err)
+	}
+
+	bundle, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("CA bundle %s does not contain any certificates", caFile)
+	}
+
+	return pool, nil
+}
+
+func newHTTPClient(rootCAs *x509.CertPool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	return &http.Client{Transport: transport, Timeout: time.Minute}
+}
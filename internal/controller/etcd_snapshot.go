@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileEtcdSnapshot takes an on-demand etcd snapshot on one of the control plane nodes
+// and records it in the status. Returns whether the snapshot has been completed.
+func (r *UpgradePlanReconciler) reconcileEtcdSnapshot(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	conditionType string,
+	kubernetesVersion string,
+	nodeList *corev1.NodeList,
+) (ctrl.Result, bool, error) {
+	snapshotName := upgrade.EtcdSnapshotName(currentReleaseVersion(upgradePlan), upgradePlan.Status.SUCNameSuffix)
+
+	snapshot := upgradePlan.Status.EtcdSnapshot
+	if snapshot == nil || snapshot.Name != snapshotName {
+		node := findEtcdSnapshotNode(nodeList)
+		if node == nil {
+			setFailedCondition(upgradePlan, conditionType, "Unable to take an etcd snapshot, none of the ready nodes run an embedded etcd")
+			return ctrl.Result{Requeue: true}, false, nil
+		}
+
+		snapshot = &lifecyclev1alpha1.EtcdSnapshotStatus{Name: snapshotName, Node: node.Name}
+		upgradePlan.Status.EtcdSnapshot = snapshot
+	} else if snapshot.CompletedAt != nil {
+		return ctrl.Result{}, true, nil
+	}
+
+	hostname := snapshot.Node
+	for _, node := range nodeList.Items {
+		if node.Name == snapshot.Node && node.Labels[corev1.LabelHostname] != "" {
+			hostname = node.Labels[corev1.LabelHostname]
+		}
+	}
+
+	identifierLabels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
+	plan := upgrade.EtcdSnapshotPlan(upgradePlan.Status.SUCNameSuffix, kubernetesVersion, snapshotName, hostname, identifierLabels)
+
+	inProgressMessage := fmt.Sprintf("Taking etcd snapshot '%s' on node %s", snapshotName, snapshot.Node)
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(plan), plan); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, false, err
+		}
+
+		setInProgressCondition(upgradePlan, conditionType, inProgressMessage)
+		return ctrl.Result{}, false, r.createObject(ctx, upgradePlan, plan)
+	}
+
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(upgrade.SUCNamespace), client.MatchingLabels{upgrade.SUCPlanLabel: plan.Name}); err != nil {
+		return ctrl.Result{}, false, fmt.Errorf("listing etcd snapshot jobs: %w", err)
+	}
+
+	var condition *batchv1.JobCondition
+	if job := latestNodeJob(jobList.Items, snapshot.Node); job != nil {
+		condition = finishedJobCondition(job)
+	}
+
+	switch {
+	case condition == nil:
+		setInProgressCondition(upgradePlan, conditionType, inProgressMessage)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, false, nil
+	case condition.Type == batchv1.JobFailed:
+		message := fmt.Sprintf("Taking etcd snapshot '%s' on node %s failed: %s", snapshotName, snapshot.Node, condition.Message)
+
+		r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "EtcdSnapshotFailed", message)
+		setFailedCondition(upgradePlan, conditionType, message)
+		return ctrl.Result{Requeue: true}, false, nil
+	}
+
+	now := metav1.Now()
+	snapshot.CompletedAt = &now
+
+	r.Recorder.Eventf(upgradePlan, corev1.EventTypeNormal, "EtcdSnapshotCompleted",
+		"Etcd snapshot '%s' taken on node %s", snapshotName, snapshot.Node)
+	return ctrl.Result{}, true, nil
+}
+
+// findEtcdSnapshotNode returns the first ready node which runs an embedded etcd, if any.
+func findEtcdSnapshotNode(nodeList *corev1.NodeList) *corev1.Node {
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if node.Labels[upgrade.EtcdLabel] == "true" && isNodeReady(node) {
+			return node
+		}
+	}
+
+	return nil
+}
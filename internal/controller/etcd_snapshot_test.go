@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindEtcdSnapshotNode(t *testing.T) {
+	node := func(name string, etcd bool, ready corev1.ConditionStatus) corev1.Node {
+		n := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+		if etcd {
+			n.Labels[upgrade.EtcdLabel] = "true"
+		}
+		return n
+	}
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			node("cp-1", true, corev1.ConditionFalse),
+			node("cp-2", true, corev1.ConditionTrue),
+			node("worker-1", false, corev1.ConditionTrue),
+		},
+	}
+
+	snapshotNode := findEtcdSnapshotNode(nodeList)
+	require.NotNil(t, snapshotNode)
+	assert.Equal(t, "cp-2", snapshotNode.Name)
+
+	nodeList.Items = nodeList.Items[2:]
+	assert.Nil(t, findEtcdSnapshotNode(nodeList))
+}
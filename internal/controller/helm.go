@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"strconv"
 
 	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
 	"github.com/suse-edge/upgrade-controller/internal/upgrade"
@@ -48,7 +49,8 @@ func newHelmClient() (*helmstorage.Storage, error) {
 	return storage, nil
 }
 
-func retrieveHelmRelease(name string) (*helmrelease.Release, error) {
+// Retrieves the revisions of a Helm release, sorted from the newest to the oldest.
+func retrieveHelmReleaseHistory(name string) ([]*helmrelease.Release, error) {
 	helmClient, err := newHelmClient()
 	if err != nil {
 		return nil, fmt.Errorf("initializing helm client: %w", err)
@@ -64,9 +66,37 @@ func retrieveHelmRelease(name string) (*helmrelease.Release, error) {
 	}
 
 	helmutil.Reverse(helmReleases, helmutil.SortByRevision)
-	helmRelease := helmReleases[0]
 
-	return helmRelease, nil
+	return helmReleases, nil
+}
+
+func retrieveHelmRelease(name string) (*helmrelease.Release, error) {
+	helmReleases, err := retrieveHelmReleaseHistory(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return helmReleases[0], nil
+}
+
+// Finds the most recent revision which was successfully deployed with a chart version
+// different from the one which failed to be installed.
+func previousHelmRelease(history []*helmrelease.Release, failedVersion string) *helmrelease.Release {
+	for _, r := range history {
+		if r.Info == nil || r.Chart == nil || r.Chart.Metadata == nil {
+			continue
+		}
+
+		if r.Info.Status != helmrelease.StatusDeployed && r.Info.Status != helmrelease.StatusSuperseded {
+			continue
+		}
+
+		if r.Chart.Metadata.Version != failedVersion {
+			return r
+		}
+	}
+
+	return nil
 }
 
 func compareChartReleaseWithVersion(releaseName string, version string) (bool, error) {
@@ -78,18 +108,53 @@ func compareChartReleaseWithVersion(releaseName string, version string) (bool, e
 	return helmRelease.Chart.Metadata.Version == version, nil
 }
 
+// helmChartSource identifies where the chart of a HelmChart resource is installed from.
+type helmChartSource struct {
+	Chart        string `json:"chart,omitempty"`
+	Repo         string `json:"repo,omitempty"`
+	ChartContent string `json:"chartContent,omitempty"`
+}
+
+// Records the current chart source of a HelmChart resource so that it can be restored on rollback.
+func savePreviousChartSource(chart *helmcattlev1.HelmChart) error {
+	previous, err := json.Marshal(helmChartSource{
+		Chart:        chart.Spec.Chart,
+		Repo:         chart.Spec.Repo,
+		ChartContent: chart.Spec.ChartContent,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling previous chart source: %w", err)
+	}
+
+	chart.Annotations[upgrade.PreviousChartSourceAnnotation] = string(previous)
+	return nil
+}
+
+// Restores the chart source of a HelmChart resource recorded prior to its upgrade, if any.
+func restorePreviousChartSource(chart *helmcattlev1.HelmChart) error {
+	previous, ok := chart.Annotations[upgrade.PreviousChartSourceAnnotation]
+	if !ok {
+		return nil
+	}
+
+	var source helmChartSource
+	if err := json.Unmarshal([]byte(previous), &source); err != nil {
+		return fmt.Errorf("unmarshaling previous chart source: %w", err)
+	}
+
+	chart.Spec.Chart = source.Chart
+	chart.Spec.Repo = source.Repo
+	chart.Spec.ChartContent = source.ChartContent
+
+	delete(chart.Annotations, upgrade.PreviousChartSourceAnnotation)
+	return nil
+}
+
 // Updates an existing HelmChart resource in order to trigger an upgrade.
 func (r *UpgradePlanReconciler) updateHelmChart(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan, chart *helmcattlev1.HelmChart, releaseChart *lifecyclev1alpha1.HelmChart) error {
 	backoffLimit := int32(6)
 
-	var userValues *apiextensionsv1.JSON
-	for _, h := range upgradePlan.Spec.Helm {
-		if releaseChart.Name == h.Chart {
-			userValues = h.Values
-			break
-		}
-	}
-
+	userValues := userHelmValues(upgradePlan, releaseChart)
 	values, err := mergeHelmValues(chart.Spec.ValuesContent, releaseChart.Values, userValues)
 	if err != nil {
 		return fmt.Errorf("merging chart values: %w", err)
@@ -103,9 +168,15 @@ func (r *UpgradePlanReconciler) updateHelmChart(ctx context.Context, upgradePlan
 		chart.Annotations = map[string]string{}
 	}
 
+	if err = savePreviousChartSource(chart); err != nil {
+		return err
+	}
+
 	chart.Labels[upgrade.PlanNameLabel] = upgradePlan.Name
 	chart.Labels[upgrade.PlanNamespaceLabel] = upgradePlan.Namespace
-	chart.Annotations[upgrade.ReleaseAnnotation] = upgradePlan.Spec.ReleaseVersion
+	chart.Annotations[upgrade.ReleaseAnnotation] = currentReleaseVersion(upgradePlan)
+	delete(chart.Annotations, upgrade.RollbackAnnotation)
+	delete(chart.Annotations, upgrade.RollbackRevisionAnnotation)
 	chart.Spec.ChartContent = ""
 	chart.Spec.Chart = releaseChart.Name
 	chart.Spec.Version = releaseChart.Version
@@ -121,14 +192,7 @@ func (r *UpgradePlanReconciler) updateHelmChart(ctx context.Context, upgradePlan
 func (r *UpgradePlanReconciler) createHelmChart(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan, installedChart *helmrelease.Release, releaseChart *lifecyclev1alpha1.HelmChart) error {
 	backoffLimit := int32(6)
 
-	var userValues *apiextensionsv1.JSON
-	for _, h := range upgradePlan.Spec.Helm {
-		if releaseChart.Name == h.Chart {
-			userValues = h.Values
-			break
-		}
-	}
-
+	userValues := userHelmValues(upgradePlan, releaseChart)
 	values, err := mergeHelmValues(installedChart.Config, releaseChart.Values, userValues)
 	if err != nil {
 		return fmt.Errorf("merging chart values: %w", err)
@@ -136,7 +200,7 @@ func (r *UpgradePlanReconciler) createHelmChart(ctx context.Context, upgradePlan
 
 	labels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
 	annotations := map[string]string{
-		upgrade.ReleaseAnnotation: upgradePlan.Spec.ReleaseVersion,
+		upgrade.ReleaseAnnotation: currentReleaseVersion(upgradePlan),
 	}
 
 	chart := &helmcattlev1.HelmChart{
@@ -163,6 +227,109 @@ func (r *UpgradePlanReconciler) createHelmChart(ctx context.Context, upgradePlan
 	return r.createObject(ctx, upgradePlan, chart)
 }
 
+// Updates a HelmChart resource whose upgrade has failed in order to
+// restore the chart source, version and values of the previously installed Helm release.
+// HelmChart resources created by the controller have no recorded chart source, as the
+// previous release was not installed through them, and therefore cannot be rolled back.
+func (r *UpgradePlanReconciler) rollbackHelmChart(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan, chart *helmcattlev1.HelmChart) (upgrade.HelmChartState, error) {
+	logger := log.FromContext(ctx)
+
+	if _, ok := chart.Annotations[upgrade.PreviousChartSourceAnnotation]; !ok {
+		logger.Info("Previous chart source not found, unable to roll back", "helmChart", chart.Name)
+		return upgrade.ChartStateRollbackUnavailable, nil
+	}
+
+	history, err := retrieveHelmReleaseHistory(chart.Name)
+	if err != nil {
+		return upgrade.ChartStateUnknown, fmt.Errorf("retrieving helm release history: %w", err)
+	}
+
+	previousRelease := previousHelmRelease(history, chart.Spec.Version)
+	if previousRelease == nil {
+		logger.Info("Previous Helm release not found, unable to roll back", "helmChart", chart.Name)
+		return upgrade.ChartStateRollbackFailed, nil
+	}
+
+	values, err := mergeHelmValues(previousRelease.Config, nil, nil)
+	if err != nil {
+		return upgrade.ChartStateUnknown, fmt.Errorf("merging previous chart values: %w", err)
+	}
+
+	logger.Info("Rolling back Helm chart",
+		"helmChart", chart.Name,
+		"failedVersion", chart.Spec.Version,
+		"previousVersion", previousRelease.Chart.Metadata.Version)
+
+	if err = restorePreviousChartSource(chart); err != nil {
+		return upgrade.ChartStateUnknown, err
+	}
+
+	chart.Annotations[upgrade.RollbackAnnotation] = currentReleaseVersion(upgradePlan)
+	chart.Annotations[upgrade.RollbackRevisionAnnotation] = strconv.Itoa(history[0].Version)
+	chart.Spec.Version = previousRelease.Chart.Metadata.Version
+	chart.Spec.ValuesContent = string(values)
+
+	if err = r.Update(ctx, chart); err != nil {
+		return upgrade.ChartStateUnknown, err
+	}
+
+	return upgrade.ChartStateRollbackInProgress, nil
+}
+
+// Evaluates the state of a HelmChart resource which is being rolled back
+// based on the latest revision of the respective Helm release.
+func (r *UpgradePlanReconciler) helmChartRollbackState(ctx context.Context, chart *helmcattlev1.HelmChart, helmRelease *helmrelease.Release) (upgrade.HelmChartState, error) {
+	revision, err := strconv.Atoi(chart.Annotations[upgrade.RollbackRevisionAnnotation])
+	if err != nil {
+		return upgrade.ChartStateUnknown, fmt.Errorf("parsing rollback revision of chart %s: %w", chart.Name, err)
+	}
+
+	if helmRelease.Info == nil || helmRelease.Version <= revision {
+		// Rollback job has not produced a new revision yet.
+		return upgrade.ChartStateRollbackInProgress, nil
+	}
+
+	switch helmRelease.Info.Status {
+	case helmrelease.StatusDeployed:
+		return upgrade.ChartStateRolledBack, nil
+	case helmrelease.StatusFailed:
+		job := &batchv1.Job{}
+		if err := r.Get(ctx, types.NamespacedName{Name: chart.Status.JobName, Namespace: upgrade.KubeSystemNamespace}, job); err != nil {
+			return upgrade.ChartStateUnknown, client.IgnoreNotFound(err)
+		}
+
+		if condition := finishedJobCondition(job); condition != nil && condition.Type == batchv1.JobFailed {
+			return upgrade.ChartStateRollbackFailed, nil
+		}
+	}
+
+	return upgrade.ChartStateRollbackInProgress, nil
+}
+
+func userHelmValues(upgradePlan *lifecyclev1alpha1.UpgradePlan, releaseChart *lifecyclev1alpha1.HelmChart) *apiextensionsv1.JSON {
+	for _, h := range upgradePlan.Spec.Helm {
+		if releaseChart.Name == h.Chart {
+			return h.Values
+		}
+	}
+
+	return nil
+}
+
+func helmFailurePolicy(upgradePlan *lifecyclev1alpha1.UpgradePlan, releaseChart *lifecyclev1alpha1.HelmChart) lifecyclev1alpha1.FailurePolicy {
+	for _, h := range upgradePlan.Spec.Helm {
+		if releaseChart.Name == h.Chart && h.FailurePolicy != "" {
+			return h.FailurePolicy
+		}
+	}
+
+	if upgradePlan.Spec.FailurePolicy != "" {
+		return upgradePlan.Spec.FailurePolicy
+	}
+
+	return lifecyclev1alpha1.FailurePolicyContinue
+}
+
 func mergeHelmValues(installedValues any, releaseValues, userValues *apiextensionsv1.JSON) ([]byte, error) {
 	values := map[string]any{}
 
@@ -232,7 +399,7 @@ func mergeMaps(m1, m2 map[string]any) map[string]any {
 	return out
 }
 
-func (r *UpgradePlanReconciler) upgradeHelmChart(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan, releaseChart *lifecyclev1alpha1.HelmChart) (upgrade.HelmChartState, error) {
+func (r *UpgradePlanReconciler) upgradeHelmChart(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan, releaseChart *lifecyclev1alpha1.HelmChart, failurePolicy lifecyclev1alpha1.FailurePolicy) (upgrade.HelmChartState, error) {
 	helmRelease, err := retrieveHelmRelease(releaseChart.ReleaseName)
 	if err != nil {
 		if errors.Is(err, helmdriver.ErrReleaseNotFound) {
@@ -241,9 +408,25 @@ func (r *UpgradePlanReconciler) upgradeHelmChart(ctx context.Context, upgradePla
 		return upgrade.ChartStateUnknown, fmt.Errorf("retrieving helm release: %w", err)
 	}
 
+	return r.upgradeHelmRelease(ctx, upgradePlan, helmRelease, releaseChart, failurePolicy)
+}
+
+// upgradeHelmRelease upgrades an installed Helm release to the chart version of the release
+// currently being upgraded to and reports the state of the upgrade.
+func (r *UpgradePlanReconciler) upgradeHelmRelease(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	helmRelease *helmrelease.Release,
+	releaseChart *lifecyclev1alpha1.HelmChart,
+	failurePolicy lifecyclev1alpha1.FailurePolicy,
+) (upgrade.HelmChartState, error) {
+	// Intermediate releases of multi-hop upgrades are tracked separately,
+	// so that charts which are not changed by a release are not reported as upgraded.
+	currentRelease := currentReleaseVersion(upgradePlan)
+
 	chart := &helmcattlev1.HelmChart{}
 
-	if err = r.Get(ctx, upgrade.ChartNamespacedName(helmRelease.Name), chart); err != nil {
+	if err := r.Get(ctx, upgrade.ChartNamespacedName(helmRelease.Name), chart); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return upgrade.ChartStateUnknown, err
 		}
@@ -255,31 +438,30 @@ func (r *UpgradePlanReconciler) upgradeHelmChart(ctx context.Context, upgradePla
 		return upgrade.ChartStateInProgress, r.createHelmChart(ctx, upgradePlan, helmRelease, releaseChart)
 	}
 
+	if chart.Annotations[upgrade.RollbackAnnotation] == currentRelease {
+		return r.helmChartRollbackState(ctx, chart, helmRelease)
+	}
+
 	if chart.Spec.Version != releaseChart.Version {
 		return upgrade.ChartStateInProgress, r.updateHelmChart(ctx, upgradePlan, chart, releaseChart)
 	}
 
 	releaseVersion := chart.Annotations[upgrade.ReleaseAnnotation]
-	if releaseVersion != upgradePlan.Spec.ReleaseVersion {
+	if releaseVersion != currentRelease {
 		return upgrade.ChartStateVersionAlreadyInstalled, nil
 	}
 
 	job := &batchv1.Job{}
-	if err = r.Get(ctx, types.NamespacedName{Name: chart.Status.JobName, Namespace: upgrade.KubeSystemNamespace}, job); err != nil {
+	if err := r.Get(ctx, types.NamespacedName{Name: chart.Status.JobName, Namespace: upgrade.KubeSystemNamespace}, job); err != nil {
 		return upgrade.ChartStateUnknown, client.IgnoreNotFound(err)
 	}
 
-	idx := slices.IndexFunc(job.Status.Conditions, func(condition batchv1.JobCondition) bool {
-		return condition.Status == corev1.ConditionTrue &&
-			(condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed)
-	})
-
-	if idx == -1 {
+	condition := finishedJobCondition(job)
+	if condition == nil {
 		// Upgrade job is still ongoing.
 		return upgrade.ChartStateInProgress, nil
 	}
 
-	condition := job.Status.Conditions[idx]
 	if condition.Type == batchv1.JobComplete {
 		return upgrade.ChartStateSucceeded, nil
 	}
@@ -290,18 +472,36 @@ func (r *UpgradePlanReconciler) upgradeHelmChart(ctx context.Context, upgradePla
 		"job", fmt.Sprintf("%s/%s", job.Namespace, job.Name),
 		"jobStatus", condition.Message)
 
+	if failurePolicy == lifecyclev1alpha1.FailurePolicyRollback {
+		return r.rollbackHelmChart(ctx, upgradePlan, chart)
+	}
+
 	return upgrade.ChartStateFailed, nil
 }
 
+// Returns the condition indicating that a job has either completed or failed, if any.
+func finishedJobCondition(job *batchv1.Job) *batchv1.JobCondition {
+	idx := slices.IndexFunc(job.Status.Conditions, func(condition batchv1.JobCondition) bool {
+		return condition.Status == corev1.ConditionTrue &&
+			(condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed)
+	})
+
+	if idx == -1 {
+		return nil
+	}
+
+	return &job.Status.Conditions[idx]
+}
+
 func evaluateHelmChartState(state upgrade.HelmChartState) (setCondition setCondition, requeue bool) {
 	switch state {
 	case upgrade.ChartStateNotInstalled, upgrade.ChartStateVersionAlreadyInstalled:
 		return setSkippedCondition, true
-	case upgrade.ChartStateInProgress:
+	case upgrade.ChartStateInProgress, upgrade.ChartStateRollbackInProgress:
 		return setInProgressCondition, false
 	case upgrade.ChartStateSucceeded:
 		return setSuccessfulCondition, true
-	case upgrade.ChartStateFailed:
+	case upgrade.ChartStateFailed, upgrade.ChartStateRolledBack, upgrade.ChartStateRollbackFailed, upgrade.ChartStateRollbackUnavailable:
 		return setFailedCondition, true
 	default:
 		return setErrorCondition, false
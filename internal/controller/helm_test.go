@@ -1,10 +1,22 @@
 package controller
 
 import (
+	"context"
 	"testing"
 
 	"gopkg.in/yaml.v3"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	helmcattlev1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -301,3 +313,330 @@ metal3-ironic:
 		})
 	}
 }
+
+func Test_PreviousHelmRelease(t *testing.T) {
+	release := func(revision int, version string, status helmrelease.Status) *helmrelease.Release {
+		return &helmrelease.Release{
+			Version: revision,
+			Info:    &helmrelease.Info{Status: status},
+			Chart:   &helmchart.Chart{Metadata: &helmchart.Metadata{Version: version}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		history          []*helmrelease.Release
+		expectedRevision int
+	}{
+		{
+			name: "Previous release is deployed",
+			history: []*helmrelease.Release{
+				release(3, "1.1.0", helmrelease.StatusFailed),
+				release(2, "1.0.0", helmrelease.StatusDeployed),
+				release(1, "0.9.0", helmrelease.StatusSuperseded),
+			},
+			expectedRevision: 2,
+		},
+		{
+			name: "Previous release is superseded by failed retries",
+			history: []*helmrelease.Release{
+				release(4, "1.1.0", helmrelease.StatusFailed),
+				release(3, "1.1.0", helmrelease.StatusSuperseded),
+				release(2, "1.0.0", helmrelease.StatusSuperseded),
+				release(1, "0.9.0", helmrelease.StatusSuperseded),
+			},
+			expectedRevision: 2,
+		},
+		{
+			name: "Failed releases are ignored",
+			history: []*helmrelease.Release{
+				release(3, "1.1.0", helmrelease.StatusFailed),
+				release(2, "1.0.1", helmrelease.StatusFailed),
+				release(1, "1.0.0", helmrelease.StatusSuperseded),
+			},
+			expectedRevision: 1,
+		},
+		{
+			name: "No previous release",
+			history: []*helmrelease.Release{
+				release(1, "1.1.0", helmrelease.StatusFailed),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previous := previousHelmRelease(test.history, "1.1.0")
+			if test.expectedRevision == 0 {
+				assert.Nil(t, previous)
+				return
+			}
+
+			require.NotNil(t, previous)
+			assert.Equal(t, test.expectedRevision, previous.Version)
+		})
+	}
+}
+
+func Test_HelmFailurePolicy(t *testing.T) {
+	rancher := &lifecyclev1alpha1.HelmChart{Name: "rancher"}
+
+	tests := []struct {
+		name           string
+		spec           lifecyclev1alpha1.UpgradePlanSpec
+		expectedPolicy lifecyclev1alpha1.FailurePolicy
+	}{
+		{
+			name:           "Default policy",
+			expectedPolicy: lifecyclev1alpha1.FailurePolicyContinue,
+		},
+		{
+			name: "Global policy",
+			spec: lifecyclev1alpha1.UpgradePlanSpec{
+				FailurePolicy: lifecyclev1alpha1.FailurePolicyRollback,
+			},
+			expectedPolicy: lifecyclev1alpha1.FailurePolicyRollback,
+		},
+		{
+			name: "Chart policy overrides global policy",
+			spec: lifecyclev1alpha1.UpgradePlanSpec{
+				FailurePolicy: lifecyclev1alpha1.FailurePolicyRollback,
+				Helm: []lifecyclev1alpha1.HelmValues{
+					{Chart: "rancher", FailurePolicy: lifecyclev1alpha1.FailurePolicyHalt},
+				},
+			},
+			expectedPolicy: lifecyclev1alpha1.FailurePolicyHalt,
+		},
+		{
+			name: "Policy of another chart is ignored",
+			spec: lifecyclev1alpha1.UpgradePlanSpec{
+				Helm: []lifecyclev1alpha1.HelmValues{
+					{Chart: "metal3", FailurePolicy: lifecyclev1alpha1.FailurePolicyHalt},
+				},
+			},
+			expectedPolicy: lifecyclev1alpha1.FailurePolicyContinue,
+		},
+		{
+			name: "Chart values without policy fall back to global policy",
+			spec: lifecyclev1alpha1.UpgradePlanSpec{
+				FailurePolicy: lifecyclev1alpha1.FailurePolicyHalt,
+				Helm: []lifecyclev1alpha1.HelmValues{
+					{Chart: "rancher", Values: &apiextensionsv1.JSON{Raw: []byte(`{"replicas": 3}`)}},
+				},
+			},
+			expectedPolicy: lifecyclev1alpha1.FailurePolicyHalt,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upgradePlan := &lifecyclev1alpha1.UpgradePlan{Spec: test.spec}
+			assert.Equal(t, test.expectedPolicy, helmFailurePolicy(upgradePlan, rancher))
+		})
+	}
+}
+
+func Test_RestorePreviousChartSource(t *testing.T) {
+	chart := &helmcattlev1.HelmChart{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "longhorn",
+			Namespace: upgrade.KubeSystemNamespace,
+		},
+		Spec: helmcattlev1.HelmChartSpec{
+			Chart:        "longhorn",
+			Version:      "1.6.1",
+			ChartContent: "H4sIAAAAAAAA/+zUsQ6CMBAG4M59ivsF",
+		},
+	}
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+		Spec:       lifecyclev1alpha1.UpgradePlanSpec{ReleaseVersion: "3.1.0"},
+	}
+
+	releaseChart := &lifecyclev1alpha1.HelmChart{
+		ReleaseName: "longhorn",
+		Name:        "longhorn",
+		Repository:  "https://charts.longhorn.io",
+		Version:     "1.7.1",
+	}
+
+	r := newFakeReconciler(chart)
+	ctx := context.Background()
+
+	require.NoError(t, r.updateHelmChart(ctx, upgradePlan, chart, releaseChart))
+
+	updated := &helmcattlev1.HelmChart{}
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(chart), updated))
+	assert.Equal(t, "https://charts.longhorn.io", updated.Spec.Repo)
+	assert.Equal(t, "1.7.1", updated.Spec.Version)
+	assert.Empty(t, updated.Spec.ChartContent)
+	assert.Contains(t, updated.Annotations, upgrade.PreviousChartSourceAnnotation)
+
+	require.NoError(t, restorePreviousChartSource(updated))
+	assert.Equal(t, "longhorn", updated.Spec.Chart)
+	assert.Empty(t, updated.Spec.Repo)
+	assert.Equal(t, "H4sIAAAAAAAA/+zUsQ6CMBAG4M59ivsF", updated.Spec.ChartContent)
+	assert.NotContains(t, updated.Annotations, upgrade.PreviousChartSourceAnnotation)
+
+	// HelmChart resources without a recorded source are left unchanged.
+	require.NoError(t, restorePreviousChartSource(updated))
+	assert.Equal(t, "H4sIAAAAAAAA/+zUsQ6CMBAG4M59ivsF", updated.Spec.ChartContent)
+}
+
+func Test_RollbackHelmChartWithoutPreviousSource(t *testing.T) {
+	// HelmChart resources created by the controller have no recorded chart source.
+	chart := &helmcattlev1.HelmChart{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "longhorn",
+			Namespace:   upgrade.KubeSystemNamespace,
+			Annotations: map[string]string{upgrade.ReleaseAnnotation: "3.1.0"},
+		},
+		Spec: helmcattlev1.HelmChartSpec{Chart: "longhorn", Version: "1.7.1"},
+	}
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+		Spec:       lifecyclev1alpha1.UpgradePlanSpec{ReleaseVersion: "3.1.0"},
+	}
+
+	r := newFakeReconciler(chart)
+	ctx := context.Background()
+
+	state, err := r.rollbackHelmChart(ctx, upgradePlan, chart)
+	require.NoError(t, err)
+	assert.Equal(t, upgrade.ChartStateRollbackUnavailable, state)
+
+	unchanged := &helmcattlev1.HelmChart{}
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(chart), unchanged))
+	assert.Equal(t, "1.7.1", unchanged.Spec.Version)
+	assert.NotContains(t, unchanged.Annotations, upgrade.RollbackAnnotation)
+}
+
+func Test_HelmChartRollbackState(t *testing.T) {
+	helmRelease := func(revision int, version string, status helmrelease.Status) *helmrelease.Release {
+		return &helmrelease.Release{
+			Name:    "longhorn",
+			Version: revision,
+			Info:    &helmrelease.Info{Status: status},
+			Chart:   &helmchart.Chart{Metadata: &helmchart.Metadata{Version: version}},
+		}
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "helm-install-longhorn", Namespace: upgrade.KubeSystemNamespace},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	chart := &helmcattlev1.HelmChart{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "longhorn",
+			Namespace: upgrade.KubeSystemNamespace,
+			Annotations: map[string]string{
+				upgrade.RollbackAnnotation:         "3.1.0",
+				upgrade.RollbackRevisionAnnotation: "3",
+			},
+		},
+		Spec:   helmcattlev1.HelmChartSpec{Chart: "longhorn", Version: "1.6.1"},
+		Status: helmcattlev1.HelmChartStatus{JobName: job.Name},
+	}
+
+	tests := []struct {
+		name          string
+		helmRelease   *helmrelease.Release
+		expectedState upgrade.HelmChartState
+	}{
+		{
+			name:          "Failed upgrade revision of the same chart version",
+			helmRelease:   helmRelease(3, "1.6.1", helmrelease.StatusFailed),
+			expectedState: upgrade.ChartStateRollbackInProgress,
+		},
+		{
+			name:          "Rollback revision pending",
+			helmRelease:   helmRelease(4, "1.6.1", helmrelease.StatusPendingUpgrade),
+			expectedState: upgrade.ChartStateRollbackInProgress,
+		},
+		{
+			name:          "Rollback revision deployed",
+			helmRelease:   helmRelease(4, "1.6.1", helmrelease.StatusDeployed),
+			expectedState: upgrade.ChartStateRolledBack,
+		},
+		{
+			name:          "Rollback revision failed",
+			helmRelease:   helmRelease(4, "1.6.1", helmrelease.StatusFailed),
+			expectedState: upgrade.ChartStateRollbackFailed,
+		},
+	}
+
+	r := newFakeReconciler(job)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state, err := r.helmChartRollbackState(context.Background(), chart, test.helmRelease)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedState, state)
+		})
+	}
+}
+
+func TestReconcileNormal_HaltedUpgrade(t *testing.T) {
+	release := &lifecyclev1alpha1.ReleaseManifest{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-3-1-0", Namespace: "default"},
+		Spec: lifecyclev1alpha1.ReleaseManifestSpec{
+			ReleaseVersion: "3.1.0",
+			Components: lifecyclev1alpha1.Components{
+				OperatingSystem: lifecyclev1alpha1.OperatingSystem{
+					SupportedArchs: []lifecyclev1alpha1.Arch{lifecyclev1alpha1.ArchTypeX86},
+				},
+				Workloads: lifecyclev1alpha1.Workloads{
+					Helm: []lifecyclev1alpha1.HelmChart{
+						{ReleaseName: "rancher", Name: "rancher", Version: "2.9.1", PrettyName: "Rancher"},
+						{ReleaseName: "longhorn", Name: "longhorn", Version: "1.7.1", PrettyName: "Longhorn"},
+					},
+				},
+			},
+		},
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{Architecture: "amd64"}},
+	}
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default", Generation: 1},
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			ReleaseVersion: "3.1.0",
+			FailurePolicy:  lifecyclev1alpha1.FailurePolicyHalt,
+		},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{ObservedGeneration: 1},
+	}
+
+	rancherCondition := lifecyclev1alpha1.GetChartConditionType("Rancher")
+	longhornCondition := lifecyclev1alpha1.GetChartConditionType("Longhorn")
+
+	setSuccessfulCondition(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, "All cluster nodes are upgraded")
+	setSuccessfulCondition(plan, lifecyclev1alpha1.KubernetesUpgradedCondition, "All cluster nodes are upgraded")
+	setFailedCondition(plan, rancherCondition, "Chart rancher upgrade failed")
+	setPendingCondition(plan, longhornCondition, upgradePendingMessage("Longhorn"))
+
+	r := newFakeReconciler(release, node)
+
+	_, err := r.reconcileNormal(context.Background(), plan)
+	require.NoError(t, err)
+
+	assert.Equal(t, lifecyclev1alpha1.UpgradePhaseFailed, plan.Status.Phase)
+
+	condition := meta.FindStatusCondition(plan.Status.Conditions, longhornCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, lifecyclev1alpha1.UpgradeSkipped, condition.Reason)
+
+	// The halted plan can be edited in order to retry the upgrade.
+	updated := plan.DeepCopy()
+	updated.Spec.ReleaseVersion = "3.1.1"
+
+	_, err = (&lifecyclev1alpha1.UpgradePlanValidator{}).ValidateUpdate(context.Background(), plan, updated)
+	assert.NoError(t, err)
+}
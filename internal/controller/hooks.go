@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileStageHooks runs the hooks of the specified phase for the stage one after the other.
+// Returns whether all hooks have finished and the stage can proceed.
+func (r *UpgradePlanReconciler) reconcileStageHooks(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	conditionType string,
+	phase lifecyclev1alpha1.HookPhase,
+) (ctrl.Result, bool, error) {
+	stage := strings.TrimSuffix(conditionType, componentConditionSuffix)
+
+	// Pre-upgrade hooks are run while the stage is still pending.
+	setWaitingCondition := setPendingCondition
+	if phase == lifecyclev1alpha1.HookPhasePost {
+		setWaitingCondition = setInProgressCondition
+	}
+
+	for _, hook := range upgradePlan.Spec.Hooks {
+		if hook.Phase != phase || !hook.RunsFor(stage) {
+			continue
+		}
+
+		name := upgrade.HookJobName(&hook, stage, upgradePlan.Status.SUCNameSuffix)
+
+		job := &batchv1.Job{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: upgradePlan.Namespace}, job); err != nil {
+			if !errors.IsNotFound(err) {
+				return ctrl.Result{}, false, fmt.Errorf("getting hook job %s: %w", name, err)
+			}
+
+			labels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
+			job = upgrade.HookJob(&hook, name, upgradePlan.Namespace, labels)
+
+			setWaitingCondition(upgradePlan, conditionType, hookRunningMessage(&hook))
+			return ctrl.Result{}, false, r.createObject(ctx, upgradePlan, job)
+		}
+
+		condition := finishedJobCondition(job)
+		if condition == nil {
+			setWaitingCondition(upgradePlan, conditionType, hookRunningMessage(&hook))
+			return ctrl.Result{}, false, nil
+		}
+
+		if condition.Type == batchv1.JobComplete {
+			continue
+		}
+
+		message := fmt.Sprintf("%s-upgrade hook '%s' failed: job %s/%s: %s",
+			strings.ToLower(string(hook.Phase)), hook.Name, job.Namespace, job.Name, condition.Message)
+
+		r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "HookFailed", message)
+
+		if hook.FailurePolicy == lifecyclev1alpha1.HookFailurePolicyContinue {
+			continue
+		}
+
+		setFailedCondition(upgradePlan, conditionType, message)
+		return ctrl.Result{Requeue: true}, false, nil
+	}
+
+	return ctrl.Result{}, true, nil
+}
+
+func hookRunningMessage(hook *lifecyclev1alpha1.StageHook) string {
+	return fmt.Sprintf("Running %s-upgrade hook '%s'", strings.ToLower(string(hook.Phase)), hook.Name)
+}
+
+func (r *UpgradePlanReconciler) deleteHookJobs(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) error {
+	jobs := &batchv1.JobList{}
+	labels := client.MatchingLabels(upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace))
+
+	if err := r.List(ctx, jobs, client.InNamespace(upgradePlan.Namespace), labels, client.HasLabels{upgrade.HookLabel}); err != nil {
+		return fmt.Errorf("retrieving hook jobs: %w", err)
+	}
+
+	for _, job := range jobs.Items {
+		if err := r.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting hook job %s: %w", job.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maintenanceWindowWait returns the time remaining until the next maintenance window opens.
+// Zero is returned if no maintenance windows are configured or one of them is currently open.
+func maintenanceWindowWait(windows []lifecyclev1alpha1.MaintenanceWindow, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+
+	for _, window := range windows {
+		schedule, err := cron.ParseStandard(window.Schedule)
+		if err != nil {
+			return 0, fmt.Errorf("parsing maintenance window schedule '%s': %w", window.Schedule, err)
+		}
+
+		location := time.UTC
+		if window.TimeZone != "" {
+			if location, err = time.LoadLocation(window.TimeZone); err != nil {
+				return 0, fmt.Errorf("loading maintenance window time zone: %w", err)
+			}
+		}
+
+		localNow := now.In(location)
+
+		// The window is currently open if it was last opened less than its duration ago.
+		if !schedule.Next(localNow.Add(-window.Duration.Duration)).After(localNow) {
+			return 0, nil
+		}
+
+		if next := schedule.Next(localNow).Sub(localNow); wait == 0 || next < wait {
+			wait = next
+		}
+	}
+
+	return wait, nil
+}
+
+// awaitMaintenanceWindow reports whether the creation of new upgrade resources must be postponed
+// and returns the time after which the plan should be reconciled again.
+// The wait is surfaced under the WaitingForMaintenanceWindow condition, which is removed once a window opens.
+func awaitMaintenanceWindow(plan *lifecyclev1alpha1.UpgradePlan, conditionType string) (time.Duration, error) {
+	now := time.Now()
+
+	wait, err := maintenanceWindowWait(plan.Spec.MaintenanceWindows, now)
+	if err != nil {
+		return 0, err
+	} else if wait == 0 {
+		meta.RemoveStatusCondition(&plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition)
+		endWaitPeriod(plan, lifecyclev1alpha1.OutsideMaintenanceWindowReason)
+		return 0, nil
+	}
+
+	// Time spent waiting for a window does not count towards the stage timeout.
+	startWaitPeriod(plan, lifecyclev1alpha1.OutsideMaintenanceWindowReason)
+
+	message := fmt.Sprintf("Waiting for maintenance window, next window opens at %s", now.Add(wait).UTC().Format(time.RFC3339))
+	setMaintenanceWindowCondition(plan, conditionType, lifecyclev1alpha1.OutsideMaintenanceWindowReason, message)
+
+	return wait, nil
+}
+
+// reconcileMaintenanceWindow suspends the SUC Plans of a running OS or Kubernetes upgrade once the maintenance window closes
+// and restores them as soon as another window opens. Helm chart upgrades cannot be suspended and are left running.
+// Returns the time after which the plan should be reconciled again while the upgrade is suspended.
+func (r *UpgradePlanReconciler) reconcileMaintenanceWindow(ctx context.Context, plan *lifecyclev1alpha1.UpgradePlan) (time.Duration, error) {
+	now := time.Now()
+
+	wait, err := maintenanceWindowWait(plan.Spec.MaintenanceWindows, now)
+	if err != nil {
+		return 0, err
+	}
+
+	condition := meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition)
+	suspended := condition != nil && condition.Reason == lifecyclev1alpha1.MaintenanceWindowClosedReason
+
+	if wait == 0 {
+		if !suspended {
+			return 0, nil
+		}
+
+		if err = r.restoreSUCPlans(ctx, plan); err != nil {
+			return 0, err
+		}
+
+		meta.RemoveStatusCondition(&plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition)
+		endWaitPeriod(plan, lifecyclev1alpha1.OutsideMaintenanceWindowReason)
+
+		r.Recorder.Event(plan, corev1.EventTypeNormal, "UpgradeResumed", "Maintenance window is open, upgrade is resumed")
+		return 0, nil
+	}
+
+	conditionType := runningNodeUpgrade(plan)
+	if conditionType == "" {
+		return 0, nil
+	}
+
+	if err = r.suspendSUCPlans(ctx, plan); err != nil {
+		return 0, err
+	}
+
+	message := fmt.Sprintf("Suspended outside of maintenance window, next window opens at %s", now.Add(wait).UTC().Format(time.RFC3339))
+	setMaintenanceWindowCondition(plan, conditionType, lifecyclev1alpha1.MaintenanceWindowClosedReason, message)
+
+	// Time spent suspended does not count towards the stage timeout.
+	startWaitPeriod(plan, lifecyclev1alpha1.OutsideMaintenanceWindowReason)
+
+	if !suspended {
+		r.Recorder.Event(plan, corev1.EventTypeNormal, "UpgradeSuspended", "Maintenance window is closed, upgrade is suspended")
+	}
+
+	return wait, nil
+}
+
+// runningNodeUpgrade returns the condition type of the OS or Kubernetes upgrade which is currently in progress, if any.
+func runningNodeUpgrade(plan *lifecyclev1alpha1.UpgradePlan) string {
+	for _, conditionType := range []string{lifecyclev1alpha1.OperatingSystemUpgradedCondition, lifecyclev1alpha1.KubernetesUpgradedCondition} {
+		condition := meta.FindStatusCondition(plan.Status.Conditions, conditionType)
+		if condition != nil && condition.Reason == lifecyclev1alpha1.UpgradeInProgress {
+			return conditionType
+		}
+	}
+
+	return ""
+}
+
+// setMaintenanceWindowCondition surfaces the reason why the upgrade is waiting for a maintenance window.
+func setMaintenanceWindowCondition(plan *lifecyclev1alpha1.UpgradePlan, conditionType, reason, message string) {
+	meta.SetStatusCondition(&plan.Status.Conditions, metav1.Condition{
+		Type:    lifecyclev1alpha1.MaintenanceWindowCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+
+	// Preserve the current state of the stage condition and only surface the reason for the wait.
+	condition := meta.FindStatusCondition(plan.Status.Conditions, conditionType)
+	if condition == nil {
+		setPendingCondition(plan, conditionType, message)
+	} else if condition.Message != message {
+		meta.SetStatusCondition(&plan.Status.Conditions, metav1.Condition{
+			Type:    condition.Type,
+			Status:  condition.Status,
+			Reason:  condition.Reason,
+			Message: message,
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestMaintenanceWindowWait(t *testing.T) {
+	nightly := lifecyclev1alpha1.MaintenanceWindow{
+		Schedule: "0 22 * * *",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}
+
+	berlin := nightly
+	berlin.TimeZone = "Europe/Berlin"
+
+	weekend := lifecyclev1alpha1.MaintenanceWindow{
+		Schedule: "0 8 * * 6",
+		Duration: metav1.Duration{Duration: 2 * time.Hour},
+	}
+
+	tests := []struct {
+		name         string
+		windows      []lifecyclev1alpha1.MaintenanceWindow
+		now          time.Time
+		expectedWait time.Duration
+	}{
+		{
+			name:         "No windows",
+			now:          time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC),
+			expectedWait: 0,
+		},
+		{
+			name:         "Window is open",
+			windows:      []lifecyclev1alpha1.MaintenanceWindow{nightly},
+			now:          time.Date(2026, 10, 14, 23, 30, 0, 0, time.UTC),
+			expectedWait: 0,
+		},
+		{
+			name:         "Window is open past midnight",
+			windows:      []lifecyclev1alpha1.MaintenanceWindow{nightly},
+			now:          time.Date(2026, 10, 15, 1, 59, 0, 0, time.UTC),
+			expectedWait: 0,
+		},
+		{
+			name:         "Window has closed",
+			windows:      []lifecyclev1alpha1.MaintenanceWindow{nightly},
+			now:          time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC),
+			expectedWait: 20 * time.Hour,
+		},
+		{
+			name:         "Window in time zone",
+			windows:      []lifecyclev1alpha1.MaintenanceWindow{berlin},
+			now:          time.Date(2026, 10, 14, 19, 0, 0, 0, time.UTC),
+			expectedWait: 1 * time.Hour,
+		},
+		{
+			name:         "Closest of multiple windows",
+			windows:      []lifecyclev1alpha1.MaintenanceWindow{nightly, weekend},
+			now:          time.Date(2026, 10, 17, 7, 0, 0, 0, time.UTC),
+			expectedWait: 1 * time.Hour,
+		},
+		{
+			name:         "Any of multiple windows is open",
+			windows:      []lifecyclev1alpha1.MaintenanceWindow{weekend, nightly},
+			now:          time.Date(2026, 10, 14, 22, 0, 0, 0, time.UTC),
+			expectedWait: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wait, err := maintenanceWindowWait(test.windows, test.now)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedWait, wait)
+		})
+	}
+}
+
+func TestMaintenanceWindowWait_InvalidSchedule(t *testing.T) {
+	windows := []lifecyclev1alpha1.MaintenanceWindow{{Schedule: "every night"}}
+
+	_, err := maintenanceWindowWait(windows, time.Now())
+	assert.ErrorContains(t, err, "parsing maintenance window schedule 'every night'")
+}
+
+// closedMaintenanceWindow returns a daily window which opens in about two hours.
+func closedMaintenanceWindow() lifecyclev1alpha1.MaintenanceWindow {
+	return lifecyclev1alpha1.MaintenanceWindow{
+		Schedule: fmt.Sprintf("0 %d * * *", (time.Now().UTC().Hour()+2)%24),
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+}
+
+func TestAwaitMaintenanceWindow(t *testing.T) {
+	conditionType := lifecyclev1alpha1.OperatingSystemUpgradedCondition
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			MaintenanceWindows: []lifecyclev1alpha1.MaintenanceWindow{closedMaintenanceWindow()},
+		},
+	}
+	setPendingCondition(plan, conditionType, upgradePendingMessage("OS"))
+
+	wait, err := awaitMaintenanceWindow(plan, conditionType)
+	require.NoError(t, err)
+	assert.Greater(t, wait, time.Hour)
+
+	condition := meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, lifecyclev1alpha1.OutsideMaintenanceWindowReason, condition.Reason)
+
+	// The state of the stage is preserved.
+	condition = meta.FindStatusCondition(plan.Status.Conditions, conditionType)
+	require.NotNil(t, condition)
+	assert.Equal(t, lifecyclev1alpha1.UpgradePending, condition.Reason)
+	assert.Contains(t, condition.Message, "Waiting for maintenance window")
+
+	// The wait is excluded from the stage timeout.
+	require.Len(t, plan.Status.WaitPeriods, 1)
+	assert.Equal(t, lifecyclev1alpha1.OutsideMaintenanceWindowReason, plan.Status.WaitPeriods[0].Reason)
+	assert.Nil(t, plan.Status.WaitPeriods[0].EndedAt)
+
+	plan.Spec.MaintenanceWindows = nil
+
+	wait, err = awaitMaintenanceWindow(plan, conditionType)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.Nil(t, meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition))
+	assert.NotNil(t, plan.Status.WaitPeriods[0].EndedAt)
+}
+
+func TestReconcileOS_OutsideMaintenanceWindow(t *testing.T) {
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			ReleaseVersion:     "3.1.0",
+			MaintenanceWindows: []lifecyclev1alpha1.MaintenanceWindow{closedMaintenanceWindow()},
+		},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{SUCNameSuffix: "abcdef"},
+	}
+	setPendingCondition(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, upgradePendingMessage("OS"))
+
+	releaseOS := &lifecyclev1alpha1.OperatingSystem{
+		Version:    "6.0",
+		ZypperID:   "SL-Micro",
+		PrettyName: "SUSE Linux Micro 6.0",
+	}
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{upgrade.ControlPlaneLabel: "true"}}},
+		},
+	}
+
+	r := newFakeReconciler()
+
+	result, err := r.reconcileOS(context.Background(), plan, "3.1.0", releaseOS, nodeList)
+	require.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, time.Hour)
+
+	assert.True(t, meta.IsStatusConditionTrue(plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition))
+
+	// Neither the OS upgrade secret nor the SUC plans are created outside the window.
+	secrets := &corev1.SecretList{}
+	require.NoError(t, r.List(context.Background(), secrets))
+	assert.Empty(t, secrets.Items)
+
+	plans := &upgradecattlev1.PlanList{}
+	require.NoError(t, r.List(context.Background(), plans))
+	assert.Empty(t, plans.Items)
+}
+
+func TestReconcileMaintenanceWindow_ClosingMidStage(t *testing.T) {
+	ctx := context.Background()
+	conditionType := lifecyclev1alpha1.KubernetesUpgradedCondition
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			ReleaseVersion:     "3.1.0",
+			MaintenanceWindows: []lifecyclev1alpha1.MaintenanceWindow{closedMaintenanceWindow()},
+		},
+	}
+	setSuccessfulCondition(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, "upgraded")
+	setInProgressCondition(plan, conditionType, "Control plane nodes are being upgraded")
+
+	sucPlan := upgrade.KubernetesControlPlanePlan("abcdef", "v1.30.3+rke2r1", nil, upgrade.PlanIdentifierLabels(plan.Name, plan.Namespace))
+	sucPlan.Spec.Concurrency = 1
+
+	r := newFakeReconciler(sucPlan)
+
+	wait, err := r.reconcileMaintenanceWindow(ctx, plan)
+	require.NoError(t, err)
+	assert.Greater(t, wait, time.Hour)
+
+	suspended := &upgradecattlev1.Plan{}
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(sucPlan), suspended))
+	assert.Zero(t, suspended.Spec.Concurrency)
+	assert.Equal(t, "1", suspended.Annotations[upgrade.SuspendedConcurrencyAnnotation])
+
+	condition := meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, lifecyclev1alpha1.MaintenanceWindowClosedReason, condition.Reason)
+
+	// The stage remains in progress and the suspension does not count towards its timeout.
+	condition = meta.FindStatusCondition(plan.Status.Conditions, conditionType)
+	require.NotNil(t, condition)
+	assert.Equal(t, lifecyclev1alpha1.UpgradeInProgress, condition.Reason)
+	assert.Contains(t, condition.Message, "Suspended outside of maintenance window")
+
+	require.Len(t, plan.Status.WaitPeriods, 1)
+	assert.Nil(t, plan.Status.WaitPeriods[0].EndedAt)
+
+	// Reconciling a suspended upgrade does not record another wait.
+	_, err = r.reconcileMaintenanceWindow(ctx, plan)
+	require.NoError(t, err)
+	require.Len(t, plan.Status.WaitPeriods, 1)
+
+	plan.Spec.MaintenanceWindows = nil
+
+	wait, err = r.reconcileMaintenanceWindow(ctx, plan)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	resumed := &upgradecattlev1.Plan{}
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(sucPlan), resumed))
+	assert.EqualValues(t, 1, resumed.Spec.Concurrency)
+	assert.NotContains(t, resumed.Annotations, upgrade.SuspendedConcurrencyAnnotation)
+
+	assert.Nil(t, meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition))
+	assert.NotNil(t, plan.Status.WaitPeriods[0].EndedAt)
+}
+
+func TestReconcileMaintenanceWindow_NoRunningNodeUpgrade(t *testing.T) {
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			ReleaseVersion:     "3.1.0",
+			MaintenanceWindows: []lifecyclev1alpha1.MaintenanceWindow{closedMaintenanceWindow()},
+		},
+	}
+	setSuccessfulCondition(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, "upgraded")
+	setSuccessfulCondition(plan, lifecyclev1alpha1.KubernetesUpgradedCondition, "upgraded")
+	setInProgressCondition(plan, "RancherUpgraded", "Chart is being upgraded")
+
+	// Running Helm chart upgrades are left to finish.
+	wait, err := newFakeReconciler().reconcileMaintenanceWindow(context.Background(), plan)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.Nil(t, meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition))
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const componentConditionSuffix = "Upgraded"
+
+var (
+	planPhaseGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "upgrade_controller_plan_phase",
+		Help: "Phase of the upgrade plan. Set to 1 for the current phase and 0 for all others.",
+	}, []string{"plan", "namespace", "release_version", "phase"})
+
+	componentStateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "upgrade_controller_component_state",
+		Help: "Upgrade state of the OS, Kubernetes and Helm chart components. Set to 1 for the current state and 0 for all others.",
+	}, []string{"plan", "namespace", "release_version", "component", "state"})
+
+	nodesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "upgrade_controller_nodes",
+		Help: "Number of nodes which are upgraded or pending in the current node upgrade stage.",
+	}, []string{"plan", "namespace", "release_version", "state"})
+
+	stageDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "upgrade_controller_stage_duration_seconds",
+		Help:    "Duration of the finished component upgrades.",
+		Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+	}, []string{"plan", "namespace", "release_version", "component", "state"})
+
+	chartFailuresCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "upgrade_controller_chart_upgrade_failures_total",
+		Help: "Number of failed Helm chart upgrades.",
+	}, []string{"plan", "namespace", "release_version", "chart"})
+)
+
+var (
+	upgradePhases = []lifecyclev1alpha1.UpgradePhase{
+		lifecyclev1alpha1.UpgradePhasePending,
+		lifecyclev1alpha1.UpgradePhaseOSUpgrade,
+		lifecyclev1alpha1.UpgradePhaseKubernetesUpgrade,
+		lifecyclev1alpha1.UpgradePhaseWorkloadUpgrade,
+		lifecyclev1alpha1.UpgradePhaseSucceeded,
+		lifecyclev1alpha1.UpgradePhaseFailed,
+	}
+
+	componentStates = []string{
+		lifecyclev1alpha1.UpgradePending,
+		lifecyclev1alpha1.UpgradeInProgress,
+		lifecyclev1alpha1.UpgradeSucceeded,
+		lifecyclev1alpha1.UpgradeFailed,
+		lifecyclev1alpha1.UpgradeSkipped,
+		lifecyclev1alpha1.UpgradeError,
+	}
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		planPhaseGauge,
+		componentStateGauge,
+		nodesGauge,
+		stageDurationHistogram,
+		chartFailuresCounter,
+	)
+}
+
+// recordMetrics updates the metrics of the upgrade plan based on its current status.
+// Previous conditions are used for observing the components which finished upgrading during the reconciliation.
+func recordMetrics(plan *lifecyclev1alpha1.UpgradePlan, previousConditions []metav1.Condition) {
+	planLabels := prometheus.Labels{"plan": plan.Name, "namespace": plan.Namespace}
+	// Multi-hop upgrades are reported under the release which is currently being upgraded to.
+	release := currentReleaseVersion(plan)
+
+	// Drop the series of the previous release versions and components.
+	planPhaseGauge.DeletePartialMatch(planLabels)
+	componentStateGauge.DeletePartialMatch(planLabels)
+	nodesGauge.DeletePartialMatch(planLabels)
+
+	if plan.Status.Phase != "" {
+		for _, phase := range upgradePhases {
+			planPhaseGauge.WithLabelValues(plan.Name, plan.Namespace, release, string(phase)).Set(boolToFloat(phase == plan.Status.Phase))
+		}
+	}
+
+	if progress := plan.Status.Progress; progress != nil {
+		nodesGauge.WithLabelValues(plan.Name, plan.Namespace, release, "upgraded").Set(float64(progress.NodesUpgraded))
+		nodesGauge.WithLabelValues(plan.Name, plan.Namespace, release, "pending").Set(float64(progress.NodesTotal - progress.NodesUpgraded))
+	}
+
+	now := time.Now()
+
+	for _, condition := range plan.Status.Conditions {
+		component, ok := strings.CutSuffix(condition.Type, componentConditionSuffix)
+		if !ok {
+			continue
+		}
+
+		for _, state := range componentStates {
+			componentStateGauge.WithLabelValues(plan.Name, plan.Namespace, release, component, state).Set(boolToFloat(state == condition.Reason))
+		}
+
+		if condition.Reason != lifecyclev1alpha1.UpgradeSucceeded && condition.Reason != lifecyclev1alpha1.UpgradeFailed {
+			continue
+		}
+
+		previous := meta.FindStatusCondition(previousConditions, condition.Type)
+		if previous != nil && previous.Reason == condition.Reason {
+			// Component has not just finished upgrading.
+			continue
+		}
+
+		if previous != nil && previous.Reason == lifecyclev1alpha1.UpgradeInProgress {
+			stageDurationHistogram.WithLabelValues(plan.Name, plan.Namespace, release, component, condition.Reason).
+				Observe(now.Sub(previous.LastTransitionTime.Time).Seconds())
+		}
+
+		if condition.Reason == lifecyclev1alpha1.UpgradeFailed &&
+			condition.Type != lifecyclev1alpha1.OperatingSystemUpgradedCondition &&
+			condition.Type != lifecyclev1alpha1.KubernetesUpgradedCondition {
+			chartFailuresCounter.WithLabelValues(plan.Name, plan.Namespace, release, component).Inc()
+		}
+	}
+}
+
+// deleteMetrics removes all metrics of a deleted upgrade plan.
+func deleteMetrics(plan *lifecyclev1alpha1.UpgradePlan) {
+	planLabels := prometheus.Labels{"plan": plan.Name, "namespace": plan.Namespace}
+
+	planPhaseGauge.DeletePartialMatch(planLabels)
+	componentStateGauge.DeletePartialMatch(planLabels)
+	nodesGauge.DeletePartialMatch(planLabels)
+	stageDurationHistogram.DeletePartialMatch(planLabels)
+	chartFailuresCounter.DeletePartialMatch(planLabels)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	startedAt := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+
+	previousConditions := []metav1.Condition{
+		{Type: lifecyclev1alpha1.OperatingSystemUpgradedCondition, Reason: lifecyclev1alpha1.UpgradeSucceeded},
+		{Type: lifecyclev1alpha1.KubernetesUpgradedCondition, Reason: lifecyclev1alpha1.UpgradeSucceeded},
+		{Type: "RancherUpgraded", Reason: lifecyclev1alpha1.UpgradeInProgress, LastTransitionTime: startedAt},
+		{Type: "Metal3Upgraded", Reason: lifecyclev1alpha1.UpgradePending},
+	}
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-plan", Namespace: "default"},
+		Spec:       lifecyclev1alpha1.UpgradePlanSpec{ReleaseVersion: "3.1.0"},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			Phase:    lifecyclev1alpha1.UpgradePhaseWorkloadUpgrade,
+			Progress: &lifecyclev1alpha1.UpgradeProgress{CurrentStage: "Metal3", NodesUpgraded: 3, NodesTotal: 3},
+			Conditions: []metav1.Condition{
+				{Type: lifecyclev1alpha1.OperatingSystemUpgradedCondition, Reason: lifecyclev1alpha1.UpgradeSucceeded},
+				{Type: lifecyclev1alpha1.KubernetesUpgradedCondition, Reason: lifecyclev1alpha1.UpgradeSucceeded},
+				{Type: "RancherUpgraded", Reason: lifecyclev1alpha1.UpgradeFailed},
+				{Type: "Metal3Upgraded", Reason: lifecyclev1alpha1.UpgradeInProgress},
+				{Type: lifecyclev1alpha1.PausedCondition, Reason: lifecyclev1alpha1.PauseRequestedReason},
+			},
+		},
+	}
+
+	recordMetrics(plan, previousConditions)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(planPhaseGauge.WithLabelValues("metrics-plan", "default", "3.1.0", "WorkloadUpgrade")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(planPhaseGauge.WithLabelValues("metrics-plan", "default", "3.1.0", "OSUpgrade")))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(componentStateGauge.WithLabelValues("metrics-plan", "default", "3.1.0", "Rancher", "Failed")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(componentStateGauge.WithLabelValues("metrics-plan", "default", "3.1.0", "Metal3", "InProgress")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(componentStateGauge.WithLabelValues("metrics-plan", "default", "3.1.0", "Metal3", "Pending")))
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(nodesGauge.WithLabelValues("metrics-plan", "default", "3.1.0", "upgraded")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(nodesGauge.WithLabelValues("metrics-plan", "default", "3.1.0", "pending")))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(chartFailuresCounter.WithLabelValues("metrics-plan", "default", "3.1.0", "Rancher")))
+	assert.Equal(t, 1, testutil.CollectAndCount(stageDurationHistogram, "upgrade_controller_stage_duration_seconds"))
+
+	// Unchanged conditions are not observed again.
+	recordMetrics(plan, plan.Status.Conditions)
+	assert.Equal(t, float64(1), testutil.ToFloat64(chartFailuresCounter.WithLabelValues("metrics-plan", "default", "3.1.0", "Rancher")))
+
+	// Conditions other than component upgrades are ignored.
+	assert.Equal(t, 4*len(componentStates), testutil.CollectAndCount(componentStateGauge, "upgrade_controller_component_state"))
+
+	deleteMetrics(plan)
+	assert.Equal(t, 0, testutil.CollectAndCount(planPhaseGauge, "upgrade_controller_plan_phase"))
+	assert.Equal(t, 0, testutil.CollectAndCount(chartFailuresCounter, "upgrade_controller_chart_upgrade_failures_total"))
+	assert.Equal(t, 0, testutil.CollectAndCount(stageDurationHistogram, "upgrade_controller_stage_duration_seconds"))
+}
+
+func TestRecordMetrics_CurrentHop(t *testing.T) {
+	now := metav1.Now()
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "hops-plan", Namespace: "default"},
+		Spec:       lifecyclev1alpha1.UpgradePlanSpec{ReleaseVersion: "3.2.0"},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			Phase: lifecyclev1alpha1.UpgradePhaseOSUpgrade,
+			Hops: []lifecyclev1alpha1.ReleaseHop{
+				{ReleaseVersion: "3.1.0", StartedAt: &now},
+				{ReleaseVersion: "3.2.0"},
+			},
+		},
+	}
+
+	recordMetrics(plan, nil)
+	defer deleteMetrics(plan)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(planPhaseGauge.WithLabelValues("hops-plan", "default", "3.1.0", "OSUpgrade")))
+	assert.Equal(t, 6, testutil.CollectAndCount(planPhaseGauge, "upgrade_controller_plan_phase"))
+}
+
+func TestUpdateStatus_Conflict(t *testing.T) {
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "conflict-plan", Namespace: "default"},
+		Spec:       lifecyclev1alpha1.UpgradePlanSpec{ReleaseVersion: "3.1.0"},
+	}
+
+	previousConditions := []metav1.Condition{
+		{Type: "RancherUpgraded", Reason: lifecyclev1alpha1.UpgradeInProgress},
+	}
+
+	r := newFakeReconciler(plan)
+	defer deleteMetrics(plan)
+
+	conflicting := true
+	r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			if conflicting {
+				return apierrors.NewConflict(lifecyclev1alpha1.GroupVersion.WithResource("upgradeplans").GroupResource(), obj.GetName(), nil)
+			}
+
+			return c.SubResource(subResource).Update(ctx, obj, opts...)
+		},
+	})
+
+	setFailedCondition(plan, "RancherUpgraded", "Chart rancher upgrade failed")
+
+	// Failures are not counted until the status has been persisted.
+	require.Error(t, r.updateStatus(context.Background(), plan, previousConditions))
+	assert.Equal(t, 0, testutil.CollectAndCount(chartFailuresCounter, "upgrade_controller_chart_upgrade_failures_total"))
+
+	conflicting = false
+
+	require.NoError(t, r.updateStatus(context.Background(), plan, previousConditions))
+	assert.Equal(t, float64(1), testutil.ToFloat64(chartFailuresCounter.WithLabelValues("conflict-plan", "default", "3.1.0", "Rancher")))
+}
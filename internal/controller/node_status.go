@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	controlPlaneRole = "control-plane"
+	workerRole       = "worker"
+)
+
+// stagePlanNames returns the names of all SUC Plans which are used throughout a node upgrade stage.
+func stagePlanNames(
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	nodeList *corev1.NodeList,
+	controlPlanePlan *upgradecattlev1.Plan,
+	workerPlan func(batch upgrade.WorkerBatch) *upgradecattlev1.Plan,
+) ([]string, error) {
+	names := []string{controlPlanePlan.Name}
+
+	if controlPlaneOnlyCluster(nodeList) {
+		return names, nil
+	}
+
+	batches, err := workerBatches(upgradePlan, nodeList, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, batch := range batches {
+		names = append(names, workerPlan(batch).Name)
+	}
+
+	return names, nil
+}
+
+// reconcileNodeStatuses records the upgrade state of each node
+// based on the SUC jobs created for the specified plans.
+func (r *UpgradePlanReconciler) reconcileNodeStatuses(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	nodeList *corev1.NodeList,
+	target string,
+	planNames []string,
+	isUpgraded func(nodes []corev1.Node) bool,
+) error {
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(upgrade.SUCNamespace), client.HasLabels{upgrade.SUCPlanLabel}); err != nil {
+		return fmt.Errorf("listing SUC jobs: %w", err)
+	}
+
+	var jobs []batchv1.Job
+	for _, job := range jobList.Items {
+		if slices.Contains(planNames, job.Labels[upgrade.SUCPlanLabel]) {
+			jobs = append(jobs, job)
+		}
+	}
+
+	updateNodeStatuses(upgradePlan, nodeList, target, jobs, isUpgraded)
+	return nil
+}
+
+func updateNodeStatuses(
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	nodeList *corev1.NodeList,
+	target string,
+	jobs []batchv1.Job,
+	isUpgraded func(nodes []corev1.Node) bool,
+) {
+	now := metav1.Now()
+	statuses := make([]lifecyclev1alpha1.NodeStatus, 0, len(nodeList.Items))
+
+	for _, node := range nodeList.Items {
+		status := lifecyclev1alpha1.NodeStatus{
+			Name: node.Name,
+			Role: workerRole,
+		}
+
+		idx := slices.IndexFunc(upgradePlan.Status.Nodes, func(s lifecyclev1alpha1.NodeStatus) bool {
+			return s.Name == node.Name
+		})
+		if idx != -1 && upgradePlan.Status.Nodes[idx].Target == target {
+			// Preserve the state recorded during the same stage.
+			status = upgradePlan.Status.Nodes[idx]
+		}
+
+		if node.Labels[upgrade.ControlPlaneLabel] == "true" {
+			status.Role = controlPlaneRole
+		}
+
+		status.OSImage = node.Status.NodeInfo.OSImage
+		status.KubeletVersion = node.Status.NodeInfo.KubeletVersion
+		status.Target = target
+
+		job := latestNodeJob(jobs, node.Name)
+
+		switch {
+		case isUpgraded([]corev1.Node{node}):
+			status.State = lifecyclev1alpha1.UpgradeSucceeded
+			if status.CompletedAt == nil {
+				status.CompletedAt = &now
+			}
+		case job == nil:
+			// SUC jobs are garbage collected once their TTL expires.
+			// Keep the last observed state of nodes whose job is gone.
+			if status.State == "" {
+				status.State = lifecyclev1alpha1.UpgradePending
+			}
+		default:
+			status.State = lifecyclev1alpha1.UpgradeInProgress
+			if condition := finishedJobCondition(job); condition != nil && condition.Type == batchv1.JobFailed {
+				status.State = lifecyclev1alpha1.UpgradeFailed
+				status.LastError = condition.Message
+			}
+		}
+
+		if job != nil && status.StartedAt == nil {
+			status.StartedAt = &now
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	upgradePlan.Status.Nodes = statuses
+}
+
+// latestNodeJob returns the most recently created job targeting the node, if any.
+func latestNodeJob(jobs []batchv1.Job, nodeName string) *batchv1.Job {
+	var latest *batchv1.Job
+
+	for i := range jobs {
+		job := &jobs[i]
+		if job.Labels[upgrade.SUCNodeLabel] != nodeName {
+			continue
+		}
+
+		if latest == nil || latest.CreationTimestamp.Before(&job.CreationTimestamp) {
+			latest = job
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateNodeStatuses(t *testing.T) {
+	const target = "v1.30.3+rke2r1"
+
+	node := func(name, kubeletVersion string, controlPlane bool) corev1.Node {
+		n := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{OSImage: "SL Micro 6.0", KubeletVersion: kubeletVersion},
+			},
+		}
+		if controlPlane {
+			n.Labels[upgrade.ControlPlaneLabel] = "true"
+		}
+		return n
+	}
+
+	job := func(name, nodeName string, created time.Time, conditions ...batchv1.JobCondition) batchv1.Job {
+		return batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created),
+				Labels:            map[string]string{upgrade.SUCNodeLabel: nodeName},
+			},
+			Status: batchv1.JobStatus{Conditions: conditions},
+		}
+	}
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			node("cp-1", target, true),
+			node("worker-1", "v1.30.2+rke2r1", false),
+			node("worker-2", "v1.30.2+rke2r1", false),
+			node("worker-3", "v1.30.2+rke2r1", false),
+		},
+	}
+
+	now := time.Now()
+	failed := batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}
+
+	jobs := []batchv1.Job{
+		job("cp-1-job", "cp-1", now.Add(-time.Hour), batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}),
+		job("worker-1-job", "worker-1", now),
+		job("worker-2-job-old", "worker-2", now.Add(-time.Hour)),
+		job("worker-2-job", "worker-2", now, failed),
+	}
+
+	startedAt := metav1.NewTime(now.Add(-2 * time.Hour))
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			Nodes: []lifecyclev1alpha1.NodeStatus{
+				{Name: "cp-1", Target: target, State: lifecyclev1alpha1.UpgradeInProgress, StartedAt: &startedAt},
+				{Name: "worker-3", Target: "SL Micro 6.0", State: lifecyclev1alpha1.UpgradeSucceeded, LastError: "previous stage"},
+				{Name: "removed-node", Target: target},
+			},
+		},
+	}
+
+	isUpgraded := func(nodes []corev1.Node) bool {
+		return isKubernetesUpgraded(nodes, target)
+	}
+
+	// Mark all nodes as ready.
+	for i := range nodeList.Items {
+		nodeList.Items[i].Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+	}
+
+	updateNodeStatuses(upgradePlan, nodeList, target, jobs, isUpgraded)
+
+	statuses := upgradePlan.Status.Nodes
+	require.Len(t, statuses, 4)
+
+	assert.Equal(t, "cp-1", statuses[0].Name)
+	assert.Equal(t, "control-plane", statuses[0].Role)
+	assert.Equal(t, lifecyclev1alpha1.UpgradeSucceeded, statuses[0].State)
+	assert.Equal(t, &startedAt, statuses[0].StartedAt)
+	assert.NotNil(t, statuses[0].CompletedAt)
+
+	assert.Equal(t, "worker-1", statuses[1].Name)
+	assert.Equal(t, "worker", statuses[1].Role)
+	assert.Equal(t, "v1.30.2+rke2r1", statuses[1].KubeletVersion)
+	assert.Equal(t, target, statuses[1].Target)
+	assert.Equal(t, lifecyclev1alpha1.UpgradeInProgress, statuses[1].State)
+	assert.NotNil(t, statuses[1].StartedAt)
+	assert.Nil(t, statuses[1].CompletedAt)
+
+	assert.Equal(t, "worker-2", statuses[2].Name)
+	assert.Equal(t, lifecyclev1alpha1.UpgradeFailed, statuses[2].State)
+	assert.Equal(t, "BackoffLimitExceeded", statuses[2].LastError)
+
+	// State recorded for a different target is discarded.
+	assert.Equal(t, "worker-3", statuses[3].Name)
+	assert.Equal(t, lifecyclev1alpha1.UpgradePending, statuses[3].State)
+	assert.Empty(t, statuses[3].LastError)
+	assert.Nil(t, statuses[3].StartedAt)
+}
+
+func TestUpdateNodeStatusesJobRemoved(t *testing.T) {
+	const target = "v1.30.3+rke2r1"
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+				Status: corev1.NodeStatus{
+					NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.2+rke2r1"},
+				},
+			},
+		},
+	}
+
+	isUpgraded := func(nodes []corev1.Node) bool {
+		return isKubernetesUpgraded(nodes, target)
+	}
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{}
+
+	jobs := []batchv1.Job{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "worker-1-job",
+				Labels: map[string]string{upgrade.SUCNodeLabel: "worker-1"},
+			},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"},
+				},
+			},
+		},
+	}
+
+	updateNodeStatuses(upgradePlan, nodeList, target, jobs, isUpgraded)
+	require.Len(t, upgradePlan.Status.Nodes, 1)
+	assert.Equal(t, lifecyclev1alpha1.UpgradeFailed, upgradePlan.Status.Nodes[0].State)
+	startedAt := upgradePlan.Status.Nodes[0].StartedAt
+	require.NotNil(t, startedAt)
+
+	// The failed job is deleted once its TTL expires.
+	updateNodeStatuses(upgradePlan, nodeList, target, nil, isUpgraded)
+	require.Len(t, upgradePlan.Status.Nodes, 1)
+	assert.Equal(t, lifecyclev1alpha1.UpgradeFailed, upgradePlan.Status.Nodes[0].State)
+	assert.Equal(t, "BackoffLimitExceeded", upgradePlan.Status.Nodes[0].LastError)
+	assert.Equal(t, startedAt, upgradePlan.Status.Nodes[0].StartedAt)
+}
+
+func TestStagePlanNames(t *testing.T) {
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{upgrade.ControlPlaneLabel: "true"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{corev1.LabelHostname: "worker-1"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{corev1.LabelHostname: "worker-2"}}},
+		},
+	}
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			Workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Canary: &lifecyclev1alpha1.CanaryBatch{Count: 1},
+			},
+		},
+	}
+
+	controlPlanePlan := upgrade.KubernetesControlPlanePlan("abcdef", "v1.30.3+rke2r1", nil, map[string]string{})
+	workerPlan := func(batch upgrade.WorkerBatch) *upgradecattlev1.Plan {
+		return upgrade.KubernetesWorkerPlan("abcdef", "v1.30.3+rke2r1", batch, nil, map[string]string{})
+	}
+
+	names, err := stagePlanNames(upgradePlan, nodeList, controlPlanePlan, workerPlan)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"control-plane-v1-30-3-rke2r1-abcdef",
+		"workers-canary-v1-30-3-rke2r1-abcdef",
+		"workers-v1-30-3-rke2r1-abcdef",
+	}, names)
+}
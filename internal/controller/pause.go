@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pauseUpgrade suspends the SUC Plans created for the upgrade plan.
+// Nodes which are already being upgraded will finish their upgrade,
+// but SUC will not select any further nodes until the plans are resumed.
+func (r *UpgradePlanReconciler) pauseUpgrade(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) error {
+	if err := r.suspendSUCPlans(ctx, upgradePlan); err != nil {
+		return err
+	}
+
+	if meta.IsStatusConditionTrue(upgradePlan.Status.Conditions, lifecyclev1alpha1.PausedCondition) {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:    lifecyclev1alpha1.PausedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  lifecyclev1alpha1.PauseRequestedReason,
+		Message: "Upgrade is paused, no further nodes or components will be upgraded until it is resumed",
+	}
+	meta.SetStatusCondition(&upgradePlan.Status.Conditions, condition)
+
+	upgradePlan.Status.PausePeriods = append(upgradePlan.Status.PausePeriods, lifecyclev1alpha1.PausePeriod{PausedAt: metav1.Now()})
+
+	r.Recorder.Event(upgradePlan, corev1.EventTypeNormal, "UpgradePaused", "Upgrade is paused")
+	return nil
+}
+
+// resumeUpgrade restores the SUC Plans suspended by pauseUpgrade.
+// The upgrade continues from the stage recorded in the upgrade plan conditions.
+func (r *UpgradePlanReconciler) resumeUpgrade(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) error {
+	if err := r.restoreSUCPlans(ctx, upgradePlan); err != nil {
+		return err
+	}
+
+	meta.RemoveStatusCondition(&upgradePlan.Status.Conditions, lifecyclev1alpha1.PausedCondition)
+
+	if periods := upgradePlan.Status.PausePeriods; len(periods) != 0 && periods[len(periods)-1].ResumedAt == nil {
+		now := metav1.Now()
+		periods[len(periods)-1].ResumedAt = &now
+	}
+
+	r.Recorder.Event(upgradePlan, corev1.EventTypeNormal, "UpgradeResumed", "Upgrade is resumed")
+	return nil
+}
+
+// suspendSUCPlans sets the concurrency of the SUC Plans created for the upgrade plan to zero,
+// recording their original concurrency so that it can be restored later.
+func (r *UpgradePlanReconciler) suspendSUCPlans(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) error {
+	sucPlans := &upgradecattlev1.PlanList{}
+	if err := r.List(ctx, sucPlans, sucListOptions(upgradePlan)); err != nil {
+		return fmt.Errorf("retrieving SUC plans: %w", err)
+	}
+
+	for _, plan := range sucPlans.Items {
+		if _, ok := plan.Annotations[upgrade.SuspendedConcurrencyAnnotation]; ok {
+			continue
+		}
+
+		if plan.Annotations == nil {
+			plan.Annotations = map[string]string{}
+		}
+		plan.Annotations[upgrade.SuspendedConcurrencyAnnotation] = strconv.FormatInt(plan.Spec.Concurrency, 10)
+		plan.Spec.Concurrency = 0
+
+		if err := r.Update(ctx, &plan); err != nil {
+			return fmt.Errorf("suspending SUC plan %s: %w", plan.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// restoreSUCPlans restores the concurrency of the SUC Plans suspended by suspendSUCPlans.
+func (r *UpgradePlanReconciler) restoreSUCPlans(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) error {
+	sucPlans := &upgradecattlev1.PlanList{}
+	if err := r.List(ctx, sucPlans, sucListOptions(upgradePlan)); err != nil {
+		return fmt.Errorf("retrieving SUC plans: %w", err)
+	}
+
+	for _, plan := range sucPlans.Items {
+		value, ok := plan.Annotations[upgrade.SuspendedConcurrencyAnnotation]
+		if !ok {
+			continue
+		}
+
+		concurrency, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing concurrency of SUC plan %s: %w", plan.Name, err)
+		}
+
+		delete(plan.Annotations, upgrade.SuspendedConcurrencyAnnotation)
+		plan.Spec.Concurrency = concurrency
+
+		if err = r.Update(ctx, &plan); err != nil {
+			return fmt.Errorf("resuming SUC plan %s: %w", plan.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,395 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Interval at which failed pre-flight checks are retried.
+const preflightRetryInterval = time.Minute
+
+// Label shared by the etcd and control plane static pods.
+var controlPlanePodLabels = client.MatchingLabels{"tier": "control-plane"}
+
+// validationFailure describes why the upgrade to a release cannot start.
+type validationFailure struct {
+	reason  string
+	message string
+	// preflight specifies whether a pre-flight check has failed.
+	// Such failures are retried, as the cluster may recover on its own.
+	preflight bool
+	// warning specifies whether the failure is only reported and does not prevent the upgrade from starting.
+	warning bool
+}
+
+func (f *validationFailure) String() string {
+	return fmt.Sprintf("%s: %s", f.reason, f.message)
+}
+
+// reconcileReleaseValidation verifies that the upgrade to the specified release can start.
+// Used both when a new generation is observed and when the next release of a multi-hop upgrade is started.
+// Failures are reported under the ValidationFailed condition.
+func (r *UpgradePlanReconciler) reconcileReleaseValidation(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	release *lifecyclev1alpha1.ReleaseManifest,
+	nodeList *corev1.NodeList,
+) (*validationFailure, error) {
+	failures, err := r.validateRelease(ctx, upgradePlan, release, nodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	failures = slices.DeleteFunc(failures, func(failure validationFailure) bool {
+		if failure.warning {
+			r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "PreflightCheckWarning", failure.message)
+		}
+		return failure.warning
+	})
+
+	if len(failures) == 0 {
+		meta.RemoveStatusCondition(&upgradePlan.Status.Conditions, lifecyclev1alpha1.ValidationFailedCondition)
+		return nil, nil
+	}
+
+	failure := &failures[0]
+
+	log.FromContext(ctx).Info("Release validation failed",
+		"release", release.Spec.ReleaseVersion, "reason", failure.reason, "message", failure.message)
+
+	condition := metav1.Condition{
+		Type:    lifecyclev1alpha1.ValidationFailedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  failure.reason,
+		Message: failure.message,
+	}
+	if meta.SetStatusCondition(&upgradePlan.Status.Conditions, condition) && failure.preflight {
+		r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "PreflightCheckFailed", failure.message)
+	}
+
+	return failure, nil
+}
+
+// validateRelease returns the failed validations of the upgrade to the specified release.
+// Pre-flight check failures and warnings are listed last. Does not modify the UpgradePlan.
+func (r *UpgradePlanReconciler) validateRelease(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	release *lifecyclev1alpha1.ReleaseManifest,
+	nodeList *corev1.NodeList,
+) ([]validationFailure, error) {
+	failures, err := validateReleaseCompatibility(upgradePlan, release, nodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	preflightFailures, err := r.runPreflightChecks(ctx, upgradePlan, nodeList)
+	if err != nil {
+		return nil, fmt.Errorf("running pre-flight checks: %w", err)
+	}
+
+	for _, failure := range preflightFailures {
+		failure.preflight = true
+		failures = append(failures, failure)
+	}
+
+	return failures, nil
+}
+
+// validateReleaseCompatibility returns the failed validations of the upgrade to the specified release
+// which only depend on the versions the nodes are running.
+func validateReleaseCompatibility(
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	release *lifecyclev1alpha1.ReleaseManifest,
+	nodeList *corev1.NodeList,
+) ([]validationFailure, error) {
+	var failures []validationFailure
+
+	if err := release.Spec.Compatibility.ValidateNodes(nodeList.Items); err != nil {
+		failures = append(failures, validationFailure{
+			reason:  lifecyclev1alpha1.UnsupportedUpgradePathReason,
+			message: err.Error(),
+		})
+	}
+
+	if upgradePlan.Spec.Components.IncludesKubernetes() {
+		k8sDistro, err := targetKubernetesDistribution(nodeList, &release.Spec.Components.Kubernetes)
+		if err != nil {
+			return nil, fmt.Errorf("identifying target kubernetes distribution: %w", err)
+		}
+
+		if err = validateVersionSkew(upgradePlan, k8sDistro, nodeList); err != nil {
+			failures = append(failures, validationFailure{
+				reason:  lifecyclev1alpha1.UnsupportedVersionSkewReason,
+				message: err.Error(),
+			})
+		}
+	}
+
+	return failures, nil
+}
+
+// runPreflightChecks returns the first failed pre-flight check, if any,
+// preceded by the warnings of the checks performed before it.
+func (r *UpgradePlanReconciler) runPreflightChecks(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	nodeList *corev1.NodeList,
+) ([]validationFailure, error) {
+	var warnings []validationFailure
+	checks := upgradePlan.Spec.Preflight
+
+	if checks.Enabled(lifecyclev1alpha1.PreflightCheckNodeHealth) {
+		if failure := checkNodeHealth(nodeList); failure != nil {
+			return append(warnings, *failure), nil
+		}
+	}
+
+	if checks.Enabled(lifecyclev1alpha1.PreflightCheckSUCPlans) {
+		plans := &upgradecattlev1.PlanList{}
+		if err := r.List(ctx, plans); err != nil {
+			return nil, fmt.Errorf("listing SUC plans: %w", err)
+		}
+
+		if applying := findApplyingPlans(plans.Items); len(applying) != 0 {
+			return append(warnings, validationFailure{
+				reason:  lifecyclev1alpha1.SUCPlansApplyingReason,
+				message: fmt.Sprintf("SUC plans %v are still being applied", applying),
+			}), nil
+		}
+	}
+
+	if checks.Enabled(lifecyclev1alpha1.PreflightCheckControlPlaneHealth) {
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(upgrade.KubeSystemNamespace), controlPlanePodLabels); err != nil {
+			return nil, fmt.Errorf("listing control plane pods: %w", err)
+		}
+
+		if unhealthy := findUnhealthyPods(pods.Items); len(unhealthy) != 0 {
+			return append(warnings, validationFailure{
+				reason:  lifecyclev1alpha1.ControlPlaneUnhealthyReason,
+				message: fmt.Sprintf("Control plane pods %v are not running or ready", unhealthy),
+			}), nil
+		}
+	}
+
+	if checks.Enabled(lifecyclev1alpha1.PreflightCheckPodDisruptionBudgets) {
+		blocking, err := r.findBlockingDisruptionBudgets(ctx, upgradePlan, nodeList)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(blocking) != 0 {
+			failure := validationFailure{
+				reason:  lifecyclev1alpha1.DrainBlockedReason,
+				message: fmt.Sprintf("PodDisruptionBudgets %v do not allow any disruptions, node drains would be blocked", blocking),
+				warning: checks == nil || !checks.FailOnBlockingDisruptionBudgets,
+			}
+
+			if !failure.warning {
+				return append(warnings, failure), nil
+			}
+
+			warnings = append(warnings, failure)
+		}
+	}
+
+	if checks.Enabled(lifecyclev1alpha1.PreflightCheckDiskSpace) {
+		var minimum *resource.Quantity
+		if checks != nil {
+			minimum = checks.MinimumEphemeralStorage
+		}
+
+		if nodes := findNodesWithInsufficientDisk(nodeList, minimum); len(nodes) != 0 {
+			return append(warnings, validationFailure{
+				reason:  lifecyclev1alpha1.InsufficientDiskSpaceReason,
+				message: fmt.Sprintf("Nodes %v do not have enough disk space", nodes),
+			}), nil
+		}
+	}
+
+	return warnings, nil
+}
+
+// findBlockingDisruptionBudgets returns the PodDisruptionBudgets which do not allow any disruptions
+// and cover pods running on nodes whose drain is enabled.
+func (r *UpgradePlanReconciler) findBlockingDisruptionBudgets(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	nodeList *corev1.NodeList,
+) ([]string, error) {
+	drainedNodes := findDrainedNodes(nodeList, upgradePlan)
+	if len(drainedNodes) == 0 {
+		return nil, nil
+	}
+
+	budgets := &policyv1.PodDisruptionBudgetList{}
+	if err := r.List(ctx, budgets); err != nil {
+		return nil, fmt.Errorf("listing pod disruption budgets: %w", err)
+	}
+
+	if !slices.ContainsFunc(budgets.Items, isDisruptionBudgetExhausted) {
+		return nil, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+
+	return blockingDisruptionBudgets(budgets.Items, pods.Items, drainedNodes)
+}
+
+// findDrainedNodes returns the names of the nodes which are drained during their upgrade.
+func findDrainedNodes(nodeList *corev1.NodeList, upgradePlan *lifecyclev1alpha1.UpgradePlan) map[string]bool {
+	drainControlPlane, drainWorker := parseDrainOptions(nodeList, upgradePlan)
+	drained := map[string]bool{}
+
+	for _, node := range nodeList.Items {
+		controlPlane := node.Labels[upgrade.ControlPlaneLabel] == "true"
+		if (controlPlane && drainControlPlane != nil) || (!controlPlane && drainWorker != nil) {
+			drained[node.Name] = true
+		}
+	}
+
+	return drained
+}
+
+func isDisruptionBudgetExhausted(budget policyv1.PodDisruptionBudget) bool {
+	return budget.Status.ExpectedPods > 0 && budget.Status.DisruptionsAllowed == 0
+}
+
+// blockingDisruptionBudgets returns the exhausted PodDisruptionBudgets
+// which select at least one pod running on any of the drained nodes.
+func blockingDisruptionBudgets(budgets []policyv1.PodDisruptionBudget, pods []corev1.Pod, drainedNodes map[string]bool) ([]string, error) {
+	var blocking []string
+
+	for _, budget := range budgets {
+		if !isDisruptionBudgetExhausted(budget) {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(budget.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("parsing selector of pod disruption budget %s/%s: %w", budget.Namespace, budget.Name, err)
+		}
+
+		if slices.ContainsFunc(pods, func(pod corev1.Pod) bool {
+			return pod.Namespace == budget.Namespace && drainedNodes[pod.Spec.NodeName] && selector.Matches(labels.Set(pod.Labels))
+		}) {
+			blocking = append(blocking, fmt.Sprintf("%s/%s", budget.Namespace, budget.Name))
+		}
+	}
+
+	return blocking, nil
+}
+
+func checkNodeHealth(nodeList *corev1.NodeList) *validationFailure {
+	var notReady, unschedulable []string
+
+	for _, node := range nodeList.Items {
+		if !isNodeReady(&node) {
+			notReady = append(notReady, node.Name)
+		} else if node.Spec.Unschedulable {
+			unschedulable = append(unschedulable, node.Name)
+		}
+	}
+
+	switch {
+	case len(notReady) != 0:
+		return &validationFailure{
+			reason:  lifecyclev1alpha1.NodesNotReadyReason,
+			message: fmt.Sprintf("Nodes %v are not ready", notReady),
+		}
+	case len(unschedulable) != 0:
+		return &validationFailure{
+			reason:  lifecyclev1alpha1.NodesNotReadyReason,
+			message: fmt.Sprintf("Nodes %v are not schedulable", unschedulable),
+		}
+	}
+
+	return nil
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func findApplyingPlans(plans []upgradecattlev1.Plan) []string {
+	var applying []string
+
+	for _, plan := range plans {
+		if len(plan.Status.Applying) != 0 {
+			applying = append(applying, fmt.Sprintf("%s/%s", plan.Namespace, plan.Name))
+		}
+	}
+
+	return applying
+}
+
+func findUnhealthyPods(pods []corev1.Pod) []string {
+	var unhealthy []string
+
+	for _, pod := range pods {
+		ready := false
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady {
+				ready = condition.Status == corev1.ConditionTrue
+			}
+		}
+
+		if pod.Status.Phase != corev1.PodRunning || !ready {
+			unhealthy = append(unhealthy, pod.Name)
+		}
+	}
+
+	return unhealthy
+}
+
+// findNodesWithInsufficientDisk returns the nodes which are under disk pressure
+// or report less allocatable ephemeral storage than the specified minimum.
+func findNodesWithInsufficientDisk(nodeList *corev1.NodeList, minimum *resource.Quantity) []string {
+	var nodes []string
+
+	for _, node := range nodeList.Items {
+		underPressure := false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeDiskPressure && condition.Status == corev1.ConditionTrue {
+				underPressure = true
+			}
+		}
+
+		if minimum != nil {
+			storage, ok := node.Status.Allocatable[corev1.ResourceEphemeralStorage]
+			if !ok || storage.Cmp(*minimum) < 0 {
+				underPressure = true
+			}
+		}
+
+		if underPressure {
+			nodes = append(nodes, node.Name)
+		}
+	}
+
+	return nodes
+}
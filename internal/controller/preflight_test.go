@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckNodeHealth(t *testing.T) {
+	node := func(name string, ready corev1.ConditionStatus, unschedulable bool) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		nodes           []corev1.Node
+		expectedFailure *validationFailure
+	}{
+		{
+			name:  "Healthy nodes",
+			nodes: []corev1.Node{node("node-1", corev1.ConditionTrue, false), node("node-2", corev1.ConditionTrue, false)},
+		},
+		{
+			name:  "Not ready nodes",
+			nodes: []corev1.Node{node("node-1", corev1.ConditionFalse, false), node("node-2", corev1.ConditionTrue, true)},
+			expectedFailure: &validationFailure{
+				reason:  lifecyclev1alpha1.NodesNotReadyReason,
+				message: "Nodes [node-1] are not ready",
+			},
+		},
+		{
+			name:  "Unschedulable nodes",
+			nodes: []corev1.Node{node("node-1", corev1.ConditionTrue, false), node("node-2", corev1.ConditionTrue, true)},
+			expectedFailure: &validationFailure{
+				reason:  lifecyclev1alpha1.NodesNotReadyReason,
+				message: "Nodes [node-2] are not schedulable",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedFailure, checkNodeHealth(&corev1.NodeList{Items: test.nodes}))
+		})
+	}
+}
+
+func TestFindApplyingPlans(t *testing.T) {
+	plans := []upgradecattlev1.Plan{
+		{ObjectMeta: metav1.ObjectMeta{Name: "os-upgrade", Namespace: "cattle-system"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "k8s-upgrade", Namespace: "cattle-system"},
+			Status:     upgradecattlev1.PlanStatus{Applying: []string{"node-1"}},
+		},
+	}
+
+	assert.Equal(t, []string{"cattle-system/k8s-upgrade"}, findApplyingPlans(plans))
+}
+
+func TestFindUnhealthyPods(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, ready corev1.ConditionStatus) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.PodStatus{
+				Phase:      phase,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+
+	pods := []corev1.Pod{
+		pod("etcd-node-1", corev1.PodRunning, corev1.ConditionTrue),
+		pod("etcd-node-2", corev1.PodRunning, corev1.ConditionFalse),
+		pod("kube-apiserver-node-1", corev1.PodRunning, corev1.ConditionTrue),
+		pod("kube-apiserver-node-2", corev1.PodPending, corev1.ConditionFalse),
+	}
+
+	assert.Equal(t, []string{"etcd-node-2", "kube-apiserver-node-2"}, findUnhealthyPods(pods))
+}
+
+func TestBlockingDisruptionBudgets(t *testing.T) {
+	budget := func(name string, expectedPods, disruptionsAllowed int32) policyv1.PodDisruptionBudget {
+		return policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{
+				ExpectedPods:       expectedPods,
+				DisruptionsAllowed: disruptionsAllowed,
+			},
+		}
+	}
+
+	pod := func(namespace, app, nodeName string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: app, Namespace: namespace, Labels: map[string]string{"app": app}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+
+	budgets := []policyv1.PodDisruptionBudget{
+		budget("permissive", 3, 1),
+		budget("blocking", 1, 0),
+		budget("no-pods", 0, 0),
+		budget("undrained-node", 1, 0),
+		budget("other-namespace", 1, 0),
+	}
+
+	pods := []corev1.Pod{
+		pod("default", "permissive", "worker-1"),
+		pod("default", "blocking", "worker-1"),
+		pod("default", "undrained-node", "cp-1"),
+		pod("other", "other-namespace", "worker-1"),
+	}
+
+	blocking, err := blockingDisruptionBudgets(budgets, pods, map[string]bool{"worker-1": true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default/blocking"}, blocking)
+}
+
+func TestFindDrainedNodes(t *testing.T) {
+	enabled := true
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{upgrade.ControlPlaneLabel: "true"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-2"}},
+		},
+	}
+
+	// Single control plane nodes are not drained by default.
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{}
+	assert.Equal(t, map[string]bool{"worker-1": true, "worker-2": true}, findDrainedNodes(nodeList, upgradePlan))
+
+	upgradePlan.Spec.DisableDrain = &lifecyclev1alpha1.DisableDrain{Worker: true}
+	assert.Empty(t, findDrainedNodes(nodeList, upgradePlan))
+
+	upgradePlan.Spec.Drain = &lifecyclev1alpha1.Drain{
+		ControlPlane: &lifecyclev1alpha1.DrainOptions{Enabled: &enabled},
+	}
+	assert.Equal(t, map[string]bool{"cp-1": true}, findDrainedNodes(nodeList, upgradePlan))
+}
+
+func TestRunPreflightChecks_DisruptionBudgets(t *testing.T) {
+	node := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	budget := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "my-app"}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{ExpectedPods: 1},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "default", Labels: map[string]string{"app": "my-app"}},
+		Spec:       corev1.PodSpec{NodeName: "worker-1"},
+	}
+
+	nodeList := &corev1.NodeList{Items: []corev1.Node{*node("worker-1"), *node("worker-2")}}
+	r := newFakeReconciler(budget, pod)
+	ctx := context.Background()
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{}
+
+	// Blocking budgets only result in a warning by default.
+	failures, err := r.runPreflightChecks(ctx, upgradePlan, nodeList)
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.True(t, failures[0].warning)
+	assert.Equal(t, lifecyclev1alpha1.DrainBlockedReason, failures[0].reason)
+	assert.Equal(t, "PodDisruptionBudgets [default/my-app] do not allow any disruptions, node drains would be blocked", failures[0].message)
+
+	upgradePlan.Spec.Preflight = &lifecyclev1alpha1.PreflightChecks{FailOnBlockingDisruptionBudgets: true}
+
+	failures, err = r.runPreflightChecks(ctx, upgradePlan, nodeList)
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.False(t, failures[0].warning)
+
+	// Budgets are not taken into account if the nodes hosting their pods are not drained.
+	upgradePlan.Spec.DisableDrain = &lifecyclev1alpha1.DisableDrain{Worker: true}
+
+	failures, err = r.runPreflightChecks(ctx, upgradePlan, nodeList)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+}
+
+func TestFindNodesWithInsufficientDisk(t *testing.T) {
+	node := func(name string, diskPressure corev1.ConditionStatus, storage string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeDiskPressure, Status: diskPressure}},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse(storage),
+				},
+			},
+		}
+	}
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			node("node-1", corev1.ConditionFalse, "50Gi"),
+			node("node-2", corev1.ConditionTrue, "50Gi"),
+			node("node-3", corev1.ConditionFalse, "5Gi"),
+		},
+	}
+
+	assert.Equal(t, []string{"node-2"}, findNodesWithInsufficientDisk(nodeList, nil))
+
+	minimum := resource.MustParse("10Gi")
+	assert.Equal(t, []string{"node-2", "node-3"}, findNodesWithInsufficientDisk(nodeList, &minimum))
+}
@@ -0,0 +1,393 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	helmcattlev1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// Placeholder used for the names of the previewed SUC resources.
+	// The actual suffix is only generated once the upgrade starts.
+	previewNameSuffix = "preview"
+
+	// Interval at which the preview is regenerated in order to reflect changes to the cluster.
+	previewRefreshInterval = 5 * time.Minute
+)
+
+// reconcilePreview reports the changes which the upgrade would apply to the cluster under the Preview status.
+// Failed validations are only listed in the preview, so that the state of the actual upgrade is left untouched.
+func (r *UpgradePlanReconciler) reconcilePreview(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	release *lifecyclev1alpha1.ReleaseManifest,
+	nodeList *corev1.NodeList,
+) (ctrl.Result, error) {
+	preview, err := r.previewUpgrade(ctx, upgradePlan, release, nodeList)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("generating upgrade preview: %w", err)
+	}
+
+	upgradePlan.Status.Preview = preview
+
+	condition := metav1.Condition{
+		Type:    lifecyclev1alpha1.DryRunCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  lifecyclev1alpha1.PreviewGeneratedReason,
+		Message: fmt.Sprintf("Preview of the upgrade to release %s is available in the status", release.Spec.ReleaseVersion),
+	}
+	meta.SetStatusCondition(&upgradePlan.Status.Conditions, condition)
+
+	return ctrl.Result{RequeueAfter: previewRefreshInterval}, nil
+}
+
+func (r *UpgradePlanReconciler) previewUpgrade(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	release *lifecyclev1alpha1.ReleaseManifest,
+	nodeList *corev1.NodeList,
+) (*lifecyclev1alpha1.UpgradePreview, error) {
+	preview := &lifecyclev1alpha1.UpgradePreview{
+		ObservedGeneration: upgradePlan.Generation,
+		ReleaseVersion:     release.Spec.ReleaseVersion,
+		GeneratedAt:        metav1.Now(),
+	}
+
+	upgradePath, failure, err := r.findUpgradePath(ctx, upgradePlan, release)
+	if err != nil {
+		return nil, fmt.Errorf("finding upgrade path: %w", err)
+	} else if failure != nil {
+		// The target release is still previewed as if it was upgraded to directly.
+		preview.ValidationErrors = append(preview.ValidationErrors, failure.String())
+		upgradePath = []string{release.Spec.ReleaseVersion}
+	}
+
+	for i, releaseVersion := range upgradePath {
+		hopRelease := release
+		if releaseVersion != release.Spec.ReleaseVersion {
+			if hopRelease, err = r.retrieveReleaseManifest(ctx, upgradePlan, releaseVersion); err != nil {
+				return nil, fmt.Errorf("retrieving release manifest: %w", err)
+			}
+		}
+
+		releasePreview, err := r.previewRelease(ctx, upgradePlan, hopRelease, nodeList)
+		if err != nil {
+			return nil, fmt.Errorf("previewing release %s: %w", releaseVersion, err)
+		}
+
+		if i == 0 {
+			// Pre-flight checks reflect the current state of the cluster and are only relevant to the first release.
+			failures, err := r.runPreflightChecks(ctx, upgradePlan, nodeList)
+			if err != nil {
+				return nil, fmt.Errorf("running pre-flight checks: %w", err)
+			}
+
+			for _, failure := range failures {
+				if failure.warning {
+					releasePreview.ValidationWarnings = append(releasePreview.ValidationWarnings, failure.String())
+				} else {
+					releasePreview.ValidationErrors = append(releasePreview.ValidationErrors, failure.String())
+				}
+			}
+		}
+
+		preview.Releases = append(preview.Releases, *releasePreview)
+
+		if i != len(upgradePath)-1 {
+			if nodeList, err = upgradedNodeList(upgradePlan, hopRelease, nodeList); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return preview, nil
+}
+
+// previewRelease describes the changes which the upgrade to a single release would apply to the cluster
+// and validates the upgrade against the versions reported by the specified nodes.
+func (r *UpgradePlanReconciler) previewRelease(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	release *lifecyclev1alpha1.ReleaseManifest,
+	nodeList *corev1.NodeList,
+) (*lifecyclev1alpha1.ReleasePreview, error) {
+	components := upgradePlan.Spec.Components
+
+	preview := &lifecyclev1alpha1.ReleasePreview{
+		ReleaseVersion: release.Spec.ReleaseVersion,
+	}
+
+	failures, err := validateReleaseCompatibility(upgradePlan, release, nodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, failure := range failures {
+		preview.ValidationErrors = append(preview.ValidationErrors, failure.String())
+	}
+
+	if components.IncludesOS() {
+		preview.OperatingSystem, err = previewOS(upgradePlan, release.Spec.ReleaseVersion, &release.Spec.Components.OperatingSystem, nodeList)
+		if err != nil {
+			return nil, fmt.Errorf("previewing OS upgrade: %w", err)
+		}
+	}
+
+	if components.IncludesKubernetes() {
+		preview.Kubernetes, err = previewKubernetes(upgradePlan, &release.Spec.Components.Kubernetes, nodeList)
+		if err != nil {
+			return nil, fmt.Errorf("previewing kubernetes upgrade: %w", err)
+		}
+	}
+
+	for _, chart := range release.Spec.Components.Workloads.Helm {
+		charts := slices.Concat(chart.DependencyCharts, []lifecyclev1alpha1.HelmChart{chart}, chart.AddonCharts)
+
+		for _, c := range charts {
+			if !components.IncludesChart(&chart) {
+				preview.Helm = append(preview.Helm, lifecyclev1alpha1.HelmChartPreview{
+					ReleaseName: c.ReleaseName,
+					Chart:       c.Name,
+					Repository:  c.Repository,
+					Version:     c.Version,
+					Action:      lifecyclev1alpha1.PreviewActionSkip,
+					Message:     upgradeExcludedMessage(chart.PrettyName),
+				})
+				continue
+			}
+
+			chartPreview, err := r.previewHelmChart(ctx, upgradePlan, &c)
+			if err != nil {
+				return nil, fmt.Errorf("previewing helm chart %s: %w", c.ReleaseName, err)
+			}
+
+			preview.Helm = append(preview.Helm, *chartPreview)
+		}
+	}
+
+	return preview, nil
+}
+
+// upgradedNodeList returns a copy of the nodes reporting the Kubernetes version of the specified release,
+// as they would once the upgrade to it has completed.
+func upgradedNodeList(
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	release *lifecyclev1alpha1.ReleaseManifest,
+	nodeList *corev1.NodeList,
+) (*corev1.NodeList, error) {
+	upgraded := nodeList.DeepCopy()
+
+	if !upgradePlan.Spec.Components.IncludesKubernetes() {
+		return upgraded, nil
+	}
+
+	k8sDistro, err := targetKubernetesDistribution(nodeList, &release.Spec.Components.Kubernetes)
+	if err != nil {
+		return nil, fmt.Errorf("identifying target kubernetes distribution: %w", err)
+	}
+
+	for i := range upgraded.Items {
+		upgraded.Items[i].Status.NodeInfo.KubeletVersion = k8sDistro.Version
+	}
+
+	return upgraded, nil
+}
+
+func previewOS(
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	releaseVersion string,
+	releaseOS *lifecyclev1alpha1.OperatingSystem,
+	nodeList *corev1.NodeList,
+) (*lifecyclev1alpha1.NodeUpgradePreview, error) {
+	identifierLabels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
+
+	secret, err := upgrade.OSUpgradeSecret(previewNameSuffix, releaseOS, identifierLabels)
+	if err != nil {
+		return nil, fmt.Errorf("generating OS upgrade secret: %w", err)
+	}
+
+	drainControlPlane, drainWorker := parseDrainOptions(nodeList, upgradePlan)
+
+	plans := []*upgradecattlev1.Plan{
+		upgrade.OSControlPlanePlan(previewNameSuffix, releaseVersion, secret.Name, releaseOS, drainControlPlane, identifierLabels),
+	}
+	if !controlPlaneOnlyCluster(nodeList) {
+		batches, err := workerBatches(upgradePlan, nodeList, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, batch := range batches {
+			workerLabels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
+			plans = append(plans, upgrade.OSWorkerPlan(previewNameSuffix, releaseVersion, secret.Name, releaseOS, batch, drainWorker, workerLabels))
+		}
+	}
+
+	planPreviews, err := previewPlans(plans, nodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lifecyclev1alpha1.NodeUpgradePreview{
+		Version: releaseOS.Version,
+		Secret:  secret.Name,
+		Plans:   planPreviews,
+	}, nil
+}
+
+func previewKubernetes(
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	kubernetes *lifecyclev1alpha1.Kubernetes,
+	nodeList *corev1.NodeList,
+) (*lifecyclev1alpha1.NodeUpgradePreview, error) {
+	k8sDistro, err := targetKubernetesDistribution(nodeList, kubernetes)
+	if err != nil {
+		return nil, fmt.Errorf("identifying target kubernetes distribution: %w", err)
+	}
+
+	drainControlPlane, drainWorker := parseDrainOptions(nodeList, upgradePlan)
+
+	controlPlaneLabels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
+	plans := []*upgradecattlev1.Plan{
+		upgrade.KubernetesControlPlanePlan(previewNameSuffix, k8sDistro.Version, drainControlPlane, controlPlaneLabels),
+	}
+	if !controlPlaneOnlyCluster(nodeList) {
+		batches, err := workerBatches(upgradePlan, nodeList, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, batch := range batches {
+			workerLabels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
+			plans = append(plans, upgrade.KubernetesWorkerPlan(previewNameSuffix, k8sDistro.Version, batch, drainWorker, workerLabels))
+		}
+	}
+
+	planPreviews, err := previewPlans(plans, nodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lifecyclev1alpha1.NodeUpgradePreview{
+		Version: k8sDistro.Version,
+		Plans:   planPreviews,
+	}, nil
+}
+
+func previewPlans(plans []*upgradecattlev1.Plan, nodeList *corev1.NodeList) ([]lifecyclev1alpha1.PlanPreview, error) {
+	var previews []lifecyclev1alpha1.PlanPreview
+
+	for _, plan := range plans {
+		nodes, err := findMatchingNodes(nodeList, plan.Spec.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("previewing plan %s: %w", plan.Name, err)
+		}
+
+		previews = append(previews, previewPlan(plan, nodes))
+	}
+
+	return previews, nil
+}
+
+func previewPlan(plan *upgradecattlev1.Plan, nodes []corev1.Node) lifecyclev1alpha1.PlanPreview {
+	preview := lifecyclev1alpha1.PlanPreview{
+		Name:         plan.Name,
+		NodeSelector: plan.Spec.NodeSelector,
+		Concurrency:  plan.Spec.Concurrency,
+	}
+
+	for _, node := range nodes {
+		preview.Nodes = append(preview.Nodes, node.Name)
+	}
+
+	if drain := plan.Spec.Drain; drain != nil {
+		preview.Drain = &lifecyclev1alpha1.DrainPreview{
+			GracePeriod:              drain.GracePeriod,
+			PodSelector:              drain.PodSelector,
+			SkipWaitForDeleteTimeout: drain.SkipWaitForDeleteTimeout,
+			Force:                    drain.Force,
+			DisableEviction:          drain.DisableEviction,
+			DeleteEmptydirData:       drain.DeleteEmptydirData != nil && *drain.DeleteEmptydirData,
+			IgnoreDaemonSets:         drain.IgnoreDaemonSets != nil && *drain.IgnoreDaemonSets,
+		}
+
+		if drain.Timeout != nil {
+			preview.Drain.Timeout = drain.Timeout.String()
+		}
+	}
+
+	return preview
+}
+
+func (r *UpgradePlanReconciler) previewHelmChart(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	releaseChart *lifecyclev1alpha1.HelmChart,
+) (*lifecyclev1alpha1.HelmChartPreview, error) {
+	preview := &lifecyclev1alpha1.HelmChartPreview{
+		ReleaseName: releaseChart.ReleaseName,
+		Chart:       releaseChart.Name,
+		Repository:  releaseChart.Repository,
+		Version:     releaseChart.Version,
+	}
+
+	helmRelease, err := retrieveHelmRelease(releaseChart.ReleaseName)
+	if err != nil {
+		if errors.Is(err, helmdriver.ErrReleaseNotFound) {
+			preview.Action = lifecyclev1alpha1.PreviewActionSkip
+			preview.Message = upgrade.ChartStateNotInstalled.FormattedMessage(releaseChart.ReleaseName)
+			return preview, nil
+		}
+		return nil, fmt.Errorf("retrieving helm release: %w", err)
+	}
+
+	preview.InstalledVersion = helmRelease.Chart.Metadata.Version
+
+	var installedValues any
+	chart := &helmcattlev1.HelmChart{}
+
+	if err = r.Get(ctx, upgrade.ChartNamespacedName(helmRelease.Name), chart); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		if preview.InstalledVersion == releaseChart.Version {
+			preview.Action = lifecyclev1alpha1.PreviewActionSkip
+			preview.Message = upgrade.ChartStateVersionAlreadyInstalled.FormattedMessage(releaseChart.ReleaseName)
+			return preview, nil
+		}
+
+		preview.Action = lifecyclev1alpha1.PreviewActionCreate
+		installedValues = helmRelease.Config
+	} else {
+		if chart.Spec.Version == releaseChart.Version {
+			preview.Action = lifecyclev1alpha1.PreviewActionSkip
+			preview.Message = upgrade.ChartStateVersionAlreadyInstalled.FormattedMessage(releaseChart.ReleaseName)
+			return preview, nil
+		}
+
+		preview.Action = lifecyclev1alpha1.PreviewActionUpdate
+		installedValues = chart.Spec.ValuesContent
+	}
+
+	values, err := mergeHelmValues(installedValues, releaseChart.Values, userHelmValues(upgradePlan, releaseChart))
+	if err != nil {
+		return nil, fmt.Errorf("merging chart values: %w", err)
+	}
+
+	preview.Values = string(values)
+	return preview, nil
+}
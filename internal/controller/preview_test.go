@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreviewKubernetes(t *testing.T) {
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+	}
+
+	kubernetes := &lifecyclev1alpha1.Kubernetes{
+		RKE2: lifecyclev1alpha1.KubernetesDistribution{Version: "v1.30.3+rke2r1"},
+	}
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{upgrade.ControlPlaneLabel: "true"}},
+				Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.2+rke2r1"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+				Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.2+rke2r1"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-2"},
+				Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.2+rke2r1"}},
+			},
+		},
+	}
+
+	preview, err := previewKubernetes(upgradePlan, kubernetes, nodeList)
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.30.3+rke2r1", preview.Version)
+	assert.Empty(t, preview.Secret)
+	require.Len(t, preview.Plans, 2)
+
+	controlPlane := preview.Plans[0]
+	assert.Equal(t, "control-plane-v1-30-3-rke2r1-preview", controlPlane.Name)
+	assert.Equal(t, []string{"cp-1"}, controlPlane.Nodes)
+	assert.EqualValues(t, 1, controlPlane.Concurrency)
+	assert.Nil(t, controlPlane.Drain)
+
+	worker := preview.Plans[1]
+	assert.Equal(t, "workers-v1-30-3-rke2r1-preview", worker.Name)
+	assert.Equal(t, []string{"worker-1", "worker-2"}, worker.Nodes)
+	assert.EqualValues(t, 1, worker.Concurrency)
+	require.NotNil(t, worker.Drain)
+	assert.Equal(t, &lifecyclev1alpha1.DrainPreview{
+		Timeout:            "15m",
+		Force:              true,
+		DeleteEmptydirData: true,
+		IgnoreDaemonSets:   true,
+	}, worker.Drain)
+}
+
+func TestPreviewOS_ControlPlaneOnly(t *testing.T) {
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+	}
+
+	releaseOS := &lifecyclev1alpha1.OperatingSystem{
+		Version:  "6.0",
+		ZypperID: "SL-Micro",
+	}
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{upgrade.ControlPlaneLabel: "true"}}},
+		},
+	}
+
+	preview, err := previewOS(upgradePlan, "3.1.0", releaseOS, nodeList)
+	require.NoError(t, err)
+
+	assert.Equal(t, "6.0", preview.Version)
+	assert.Equal(t, "os-upgrade-secret-sl-micro-6-0-preview", preview.Secret)
+	require.Len(t, preview.Plans, 1)
+
+	assert.Equal(t, "control-plane-sl-micro-6-0-preview", preview.Plans[0].Name)
+	assert.Equal(t, []string{"cp-1"}, preview.Plans[0].Nodes)
+	assert.Nil(t, preview.Plans[0].Drain)
+}
+
+func TestReconcilePreview_Refresh(t *testing.T) {
+	generatedAt := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default", Generation: 2},
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			ReleaseVersion: "3.1.0",
+			DryRun:         true,
+			Components:     &lifecyclev1alpha1.ComponentSelection{SkipOS: true},
+		},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			ObservedGeneration: 1,
+			Preview: &lifecyclev1alpha1.UpgradePreview{
+				ObservedGeneration: 1,
+				ReleaseVersion:     "3.1.0",
+				GeneratedAt:        generatedAt,
+			},
+		},
+	}
+
+	release := &lifecyclev1alpha1.ReleaseManifest{
+		Spec: lifecyclev1alpha1.ReleaseManifestSpec{
+			ReleaseVersion: "3.1.0",
+			Components: lifecyclev1alpha1.Components{
+				Kubernetes: lifecyclev1alpha1.Kubernetes{
+					RKE2: lifecyclev1alpha1.KubernetesDistribution{Version: "v1.30.3+rke2r1"},
+				},
+			},
+		},
+	}
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			previewNode("cp-1", "v1.30.2+rke2r1", true),
+		},
+	}
+
+	r := newFakeReconciler()
+
+	result, err := r.reconcilePreview(context.Background(), upgradePlan, release, nodeList)
+	require.NoError(t, err)
+	assert.Equal(t, previewRefreshInterval, result.RequeueAfter)
+
+	// The preview does not affect the tracked generation of the actual upgrade.
+	assert.EqualValues(t, 1, upgradePlan.Status.ObservedGeneration)
+
+	preview := upgradePlan.Status.Preview
+	assert.EqualValues(t, 2, preview.ObservedGeneration)
+	assert.True(t, preview.GeneratedAt.After(generatedAt.Time))
+	assert.Empty(t, preview.ValidationErrors)
+	require.Len(t, preview.Releases, 1)
+	require.NotNil(t, preview.Releases[0].Kubernetes)
+	assert.Empty(t, preview.Releases[0].ValidationErrors)
+	require.Len(t, preview.Releases[0].Kubernetes.Plans, 1)
+	assert.Equal(t, []string{"cp-1"}, preview.Releases[0].Kubernetes.Plans[0].Nodes)
+
+	// Nodes joining the cluster are reflected in the next preview.
+	nodeList.Items = append(nodeList.Items, previewNode("worker-1", "v1.30.2+rke2r1", false))
+
+	_, err = r.reconcilePreview(context.Background(), upgradePlan, release, nodeList)
+	require.NoError(t, err)
+
+	preview = upgradePlan.Status.Preview
+	require.Len(t, preview.Releases[0].Kubernetes.Plans, 2)
+	assert.Equal(t, []string{"worker-1"}, preview.Releases[0].Kubernetes.Plans[1].Nodes)
+}
+
+func TestReconcilePreview_MultipleHops(t *testing.T) {
+	newRelease := func(releaseVersion, kubernetesVersion string, compatibility *lifecyclev1alpha1.Compatibility) *lifecyclev1alpha1.ReleaseManifest {
+		return &lifecyclev1alpha1.ReleaseManifest{
+			ObjectMeta: metav1.ObjectMeta{Name: "release-" + releaseVersion, Namespace: "default"},
+			Spec: lifecyclev1alpha1.ReleaseManifestSpec{
+				ReleaseVersion: releaseVersion,
+				Compatibility:  compatibility,
+				Components: lifecyclev1alpha1.Components{
+					Kubernetes: lifecyclev1alpha1.Kubernetes{
+						RKE2: lifecyclev1alpha1.KubernetesDistribution{Version: kubernetesVersion},
+					},
+				},
+			},
+		}
+	}
+
+	intermediate := newRelease("3.1.0", "v1.30.3+rke2r1", nil)
+	target := newRelease("3.2.0", "v1.31.1+rke2r1", &lifecyclev1alpha1.Compatibility{MinimumReleaseVersion: "3.1.0"})
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default", Generation: 1},
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			ReleaseVersion: "3.2.0",
+			DryRun:         true,
+			Components:     &lifecyclev1alpha1.ComponentSelection{SkipOS: true},
+		},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			LastSuccessfulReleaseVersion: "3.0.0",
+		},
+	}
+
+	// The worker is not ready and still runs a Kubernetes version which is too old for the intermediate release.
+	worker := previewNode("worker-1", "v1.28.9+rke2r1", false)
+	worker.Status.Conditions = nil
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			previewNode("cp-1", "v1.29.8+rke2r1", true),
+			worker,
+		},
+	}
+
+	r := newFakeReconciler(upgradePlan, intermediate, target)
+	ctx := context.Background()
+
+	_, err := r.reconcilePreview(ctx, upgradePlan, target, nodeList)
+	require.NoError(t, err)
+
+	preview := upgradePlan.Status.Preview
+	assert.Equal(t, "3.2.0", preview.ReleaseVersion)
+	assert.Empty(t, preview.ValidationErrors)
+	require.Len(t, preview.Releases, 2)
+
+	assert.Equal(t, "3.1.0", preview.Releases[0].ReleaseVersion)
+	assert.Equal(t, "v1.30.3+rke2r1", preview.Releases[0].Kubernetes.Version)
+	assert.Equal(t, []string{
+		"UnsupportedVersionSkew: upgrading node worker-1 from v1.28.9+rke2r1 to v1.30.3+rke2r1 skips a minor version which is not supported",
+		"NodesNotReady: Nodes [worker-1] are not ready",
+	}, preview.Releases[0].ValidationErrors)
+
+	// The target release is validated against the versions of the intermediate release.
+	assert.Equal(t, "3.2.0", preview.Releases[1].ReleaseVersion)
+	assert.Equal(t, "v1.31.1+rke2r1", preview.Releases[1].Kubernetes.Version)
+	assert.Empty(t, preview.Releases[1].ValidationErrors)
+
+	// Validation failures are not reported under the status conditions.
+	assert.Nil(t, meta.FindStatusCondition(upgradePlan.Status.Conditions, lifecyclev1alpha1.ValidationFailedCondition))
+	assert.Nil(t, upgradePlan.Status.Hops)
+	assert.Zero(t, upgradePlan.Status.ObservedGeneration)
+
+	// Unsupported upgrade paths are reported in the preview of the target release.
+	upgradePlan.Status.LastSuccessfulReleaseVersion = "2.0.0"
+	intermediate.Spec.Compatibility = &lifecyclev1alpha1.Compatibility{MinimumReleaseVersion: "3.0.0"}
+	require.NoError(t, r.Update(ctx, intermediate))
+
+	_, err = r.reconcilePreview(ctx, upgradePlan, target, nodeList)
+	require.NoError(t, err)
+
+	preview = upgradePlan.Status.Preview
+	assert.Equal(t, []string{
+		"UnsupportedUpgradePath: upgrading from release 2.0.0 is not supported, minimum release version is 3.1.0",
+	}, preview.ValidationErrors)
+	require.Len(t, preview.Releases, 1)
+	assert.Equal(t, "3.2.0", preview.Releases[0].ReleaseVersion)
+	assert.Nil(t, meta.FindStatusCondition(upgradePlan.Status.Conditions, lifecyclev1alpha1.ValidationFailedCondition))
+}
+
+func previewNode(name, kubeletVersion string, controlPlane bool) corev1.Node {
+	node := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+		Status: corev1.NodeStatus{
+			NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	if controlPlane {
+		node.Labels[upgrade.ControlPlaneLabel] = "true"
+	}
+
+	return node
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// resetProgress marks the beginning of the upgrade of a new plan generation.
+func resetProgress(plan *lifecyclev1alpha1.UpgradePlan, nodeList *corev1.NodeList) {
+	now := metav1.Now()
+
+	plan.Status.Phase = lifecyclev1alpha1.UpgradePhasePending
+	plan.Status.StartedAt = &now
+	plan.Status.CompletedAt = nil
+	plan.Status.Progress = &lifecyclev1alpha1.UpgradeProgress{
+		NodesTotal: len(nodeList.Items),
+	}
+}
+
+// setPhase records the phase and the stage which the upgrade is currently going through.
+func setPhase(plan *lifecyclev1alpha1.UpgradePlan, phase lifecyclev1alpha1.UpgradePhase, stage string, nodeList *corev1.NodeList) {
+	if plan.Status.Progress == nil {
+		plan.Status.Progress = &lifecyclev1alpha1.UpgradeProgress{}
+	}
+
+	progress := plan.Status.Progress
+	progress.CurrentStage = stage
+	progress.NodesTotal = len(nodeList.Items)
+
+	switch phase {
+	case lifecyclev1alpha1.UpgradePhaseWorkloadUpgrade, lifecyclev1alpha1.UpgradePhaseSucceeded:
+		progress.NodesUpgraded = progress.NodesTotal
+	case lifecyclev1alpha1.UpgradePhaseOSUpgrade, lifecyclev1alpha1.UpgradePhaseKubernetesUpgrade:
+		if plan.Status.Phase != phase {
+			progress.NodesUpgraded = 0
+		}
+	}
+
+	if (phase == lifecyclev1alpha1.UpgradePhaseSucceeded || phase == lifecyclev1alpha1.UpgradePhaseFailed) &&
+		plan.Status.CompletedAt == nil {
+		now := metav1.Now()
+		plan.Status.CompletedAt = &now
+	}
+
+	plan.Status.Phase = phase
+}
+
+// setNodeProgress records the number of nodes which have been upgraded during the current node upgrade stage.
+func setNodeProgress(plan *lifecyclev1alpha1.UpgradePlan, nodeList *corev1.NodeList, isUpgraded func(nodes []corev1.Node) bool) {
+	if plan.Status.Progress == nil {
+		plan.Status.Progress = &lifecyclev1alpha1.UpgradeProgress{}
+	}
+
+	var upgraded int
+	for _, node := range nodeList.Items {
+		if isUpgraded([]corev1.Node{node}) {
+			upgraded++
+		}
+	}
+
+	plan.Status.Progress.NodesUpgraded = upgraded
+	plan.Status.Progress.NodesTotal = len(nodeList.Items)
+}
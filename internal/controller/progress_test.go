@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradeProgress(t *testing.T) {
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{OSImage: "SL Micro 6.0"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{OSImage: "SL Micro 5.5"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}, Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{OSImage: "SL Micro 6.0"}}},
+		},
+	}
+
+	isUpgraded := func(nodes []corev1.Node) bool {
+		return nodes[0].Status.NodeInfo.OSImage == "SL Micro 6.0"
+	}
+
+	plan := &lifecyclev1alpha1.UpgradePlan{}
+
+	resetProgress(plan, nodeList)
+	assert.Equal(t, lifecyclev1alpha1.UpgradePhasePending, plan.Status.Phase)
+	assert.NotNil(t, plan.Status.StartedAt)
+	assert.Nil(t, plan.Status.CompletedAt)
+	assert.Equal(t, &lifecyclev1alpha1.UpgradeProgress{NodesTotal: 3}, plan.Status.Progress)
+
+	setPhase(plan, lifecyclev1alpha1.UpgradePhaseOSUpgrade, "OS", nodeList)
+	setNodeProgress(plan, nodeList, isUpgraded)
+	assert.Equal(t, lifecyclev1alpha1.UpgradePhaseOSUpgrade, plan.Status.Phase)
+	assert.Equal(t, &lifecyclev1alpha1.UpgradeProgress{CurrentStage: "OS", NodesUpgraded: 2, NodesTotal: 3}, plan.Status.Progress)
+
+	setPhase(plan, lifecyclev1alpha1.UpgradePhaseKubernetesUpgrade, "Kubernetes", nodeList)
+	assert.Equal(t, &lifecyclev1alpha1.UpgradeProgress{CurrentStage: "Kubernetes", NodesUpgraded: 0, NodesTotal: 3}, plan.Status.Progress)
+
+	setPhase(plan, lifecyclev1alpha1.UpgradePhaseWorkloadUpgrade, "Rancher", nodeList)
+	assert.Equal(t, &lifecyclev1alpha1.UpgradeProgress{CurrentStage: "Rancher", NodesUpgraded: 3, NodesTotal: 3}, plan.Status.Progress)
+	assert.Nil(t, plan.Status.CompletedAt)
+
+	setPhase(plan, lifecyclev1alpha1.UpgradePhaseSucceeded, "", nodeList)
+	assert.Equal(t, lifecyclev1alpha1.UpgradePhaseSucceeded, plan.Status.Phase)
+	assert.Equal(t, &lifecyclev1alpha1.UpgradeProgress{NodesUpgraded: 3, NodesTotal: 3}, plan.Status.Progress)
+	require.NotNil(t, plan.Status.CompletedAt)
+
+	completedAt := plan.Status.CompletedAt
+	setPhase(plan, lifecyclev1alpha1.UpgradePhaseSucceeded, "", nodeList)
+	assert.Same(t, completedAt, plan.Status.CompletedAt)
+}
@@ -7,19 +7,37 @@ import (
 	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
 	"github.com/suse-edge/upgrade-controller/internal/upgrade"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 func (r *UpgradePlanReconciler) reconcileHelmChart(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan, chart *lifecyclev1alpha1.HelmChart) (ctrl.Result, error) {
 	conditionType := lifecyclev1alpha1.GetChartConditionType(chart.PrettyName)
+	failurePolicy := helmFailurePolicy(upgradePlan, chart)
+
+	if condition := meta.FindStatusCondition(upgradePlan.Status.Conditions, conditionType); condition != nil && condition.Reason == lifecyclev1alpha1.UpgradePending {
+		if wait, err := awaitMaintenanceWindow(upgradePlan, conditionType); err != nil {
+			return ctrl.Result{}, err
+		} else if wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+
+		if result, finished, err := r.reconcileStageHooks(ctx, upgradePlan, conditionType, lifecyclev1alpha1.HookPhasePre); !finished || err != nil {
+			return result, err
+		}
+	}
 
 	if len(chart.DependencyCharts) != 0 {
 		for _, depChart := range chart.DependencyCharts {
-			depState, err := r.upgradeHelmChart(ctx, upgradePlan, &depChart)
+			depState, err := r.upgradeHelmChart(ctx, upgradePlan, &depChart, failurePolicy)
 			if err != nil {
 				return ctrl.Result{}, err
 			}
 
+			if depState == upgrade.ChartStateInProgress || depState == upgrade.ChartStateRollbackInProgress {
+				return r.awaitHelmChart(ctx, upgradePlan, conditionType, depState, depChart.ReleaseName)
+			}
+
 			if depState != upgrade.ChartStateSucceeded && depState != upgrade.ChartStateVersionAlreadyInstalled {
 				setCondition, requeue := evaluateHelmChartState(depState)
 				setCondition(upgradePlan, conditionType, depState.FormattedMessage(depChart.ReleaseName))
@@ -29,7 +47,7 @@ func (r *UpgradePlanReconciler) reconcileHelmChart(ctx context.Context, upgradeP
 		}
 	}
 
-	coreState, err := r.upgradeHelmChart(ctx, upgradePlan, chart)
+	coreState, err := r.upgradeHelmChart(ctx, upgradePlan, chart, failurePolicy)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -39,6 +57,10 @@ func (r *UpgradePlanReconciler) reconcileHelmChart(ctx context.Context, upgradeP
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	if coreState == upgrade.ChartStateInProgress || coreState == upgrade.ChartStateRollbackInProgress {
+		return r.awaitHelmChart(ctx, upgradePlan, conditionType, coreState, chart.ReleaseName)
+	}
+
 	if coreState != upgrade.ChartStateSucceeded && coreState != upgrade.ChartStateVersionAlreadyInstalled {
 		setCondition, requeue := evaluateHelmChartState(coreState)
 		setCondition(upgradePlan, conditionType, coreState.FormattedMessage(chart.ReleaseName))
@@ -48,7 +70,7 @@ func (r *UpgradePlanReconciler) reconcileHelmChart(ctx context.Context, upgradeP
 
 	if len(chart.AddonCharts) != 0 {
 		for _, addonChart := range chart.AddonCharts {
-			addonState, err := r.upgradeHelmChart(ctx, upgradePlan, &addonChart)
+			addonState, err := r.upgradeHelmChart(ctx, upgradePlan, &addonChart, failurePolicy)
 			if err != nil {
 				return ctrl.Result{}, err
 			}
@@ -57,16 +79,25 @@ func (r *UpgradePlanReconciler) reconcileHelmChart(ctx context.Context, upgradeP
 			case upgrade.ChartStateFailed:
 				r.Recorder.Eventf(upgradePlan, corev1.EventTypeWarning, conditionType,
 					"'%s' upgraded successfully, but add-on component '%s' failed to upgrade", chart.ReleaseName, addonChart.ReleaseName)
+			case upgrade.ChartStateRolledBack:
+				r.Recorder.Eventf(upgradePlan, corev1.EventTypeWarning, conditionType,
+					"'%s' upgraded successfully, but add-on component '%s' failed to upgrade and was rolled back", chart.ReleaseName, addonChart.ReleaseName)
+			case upgrade.ChartStateRollbackFailed, upgrade.ChartStateRollbackUnavailable:
+				r.Recorder.Eventf(upgradePlan, corev1.EventTypeWarning, conditionType,
+					"'%s' upgraded successfully, but add-on component '%s' failed to upgrade and could not be rolled back", chart.ReleaseName, addonChart.ReleaseName)
 			case upgrade.ChartStateNotInstalled:
 				r.Recorder.Eventf(upgradePlan, corev1.EventTypeNormal, conditionType,
 					"'%s' add-on component upgrade skipped as it is missing in the cluster", addonChart.ReleaseName)
 			case upgrade.ChartStateSucceeded:
 				r.Recorder.Eventf(upgradePlan, corev1.EventTypeNormal, conditionType,
 					"'%s' add-on component successfully upgraded", addonChart.ReleaseName)
-			case upgrade.ChartStateInProgress:
+			case upgrade.ChartStateInProgress, upgrade.ChartStateRollbackInProgress:
 				// mark that current add-on chart upgrade is in progress
-				setInProgressCondition(upgradePlan, conditionType, addonState.FormattedMessage(addonChart.ReleaseName))
-				return ctrl.Result{Requeue: true}, nil
+				result, err := r.awaitHelmChart(ctx, upgradePlan, conditionType, addonState, addonChart.ReleaseName)
+				if result.IsZero() {
+					result.Requeue = true
+				}
+				return result, err
 			case upgrade.ChartStateUnknown:
 				return ctrl.Result{}, nil
 			}
@@ -74,6 +105,10 @@ func (r *UpgradePlanReconciler) reconcileHelmChart(ctx context.Context, upgradeP
 	}
 
 	// to avoid confusion, when upgrade has been done, use core component message in the component condition
+	if coreState == upgrade.ChartStateSucceeded {
+		return r.completeStage(ctx, upgradePlan, conditionType, coreState.FormattedMessage(chart.ReleaseName))
+	}
+
 	setCondition, requeue := evaluateHelmChartState(coreState)
 	setCondition(upgradePlan, conditionType, coreState.FormattedMessage(chart.ReleaseName))
 	return ctrl.Result{Requeue: requeue}, nil
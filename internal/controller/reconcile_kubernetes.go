@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"slices"
-	"strings"
 	"time"
 
 	helmcattlev1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
 	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
 	"github.com/suse-edge/upgrade-controller/internal/upgrade"
 	appsv1 "k8s.io/api/apps/v1"
@@ -39,11 +39,55 @@ func (r *UpgradePlanReconciler) reconcileKubernetes(
 	identifierLabels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
 	drainControlPlane, drainWorker := parseDrainOptions(nodeList, upgradePlan)
 	controlPlanePlan := upgrade.KubernetesControlPlanePlan(nameSuffix, k8sDistro.Version, drainControlPlane, identifierLabels)
+	workerPlan := func(batch upgrade.WorkerBatch) *upgradecattlev1.Plan {
+		return upgrade.KubernetesWorkerPlan(nameSuffix, k8sDistro.Version, batch, drainWorker, identifierLabels)
+	}
+	isUpgraded := func(nodes []corev1.Node) bool {
+		return isKubernetesUpgraded(nodes, k8sDistro.Version)
+	}
+
+	setNodeProgress(upgradePlan, nodeList, isUpgraded)
+
+	planNames, err := stagePlanNames(upgradePlan, nodeList, controlPlanePlan, workerPlan)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err = r.reconcileNodeStatuses(ctx, upgradePlan, nodeList, k8sDistro.Version, planNames, isUpgraded); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.failTimedOutNodes(upgradePlan, conditionType) {
+		return ctrl.Result{}, nil
+	}
+
 	if err = r.Get(ctx, client.ObjectKeyFromObject(controlPlanePlan), controlPlanePlan); err != nil {
 		if !errors.IsNotFound(err) {
 			return ctrl.Result{}, err
 		}
 
+		// Nodes which joined the cluster since the upgrade started might be running different versions.
+		if err = validateVersionSkew(upgradePlan, k8sDistro, nodeList); err != nil {
+			setFailedCondition(upgradePlan, conditionType, err.Error())
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		if wait, err := awaitMaintenanceWindow(upgradePlan, conditionType); err != nil {
+			return ctrl.Result{}, err
+		} else if wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+
+		if result, finished, err := r.reconcileStageHooks(ctx, upgradePlan, conditionType, lifecyclev1alpha1.HookPhasePre); !finished || err != nil {
+			return result, err
+		}
+
+		if upgradePlan.Spec.EtcdSnapshot {
+			if result, finished, err := r.reconcileEtcdSnapshot(ctx, upgradePlan, conditionType, k8sDistro.Version, nodeList); !finished || err != nil {
+				return result, err
+			}
+		}
+
 		setInProgressCondition(upgradePlan, conditionType, "Control plane nodes are being upgraded")
 		return ctrl.Result{}, r.createObject(ctx, upgradePlan, controlPlanePlan)
 	}
@@ -53,7 +97,7 @@ func (r *UpgradePlanReconciler) reconcileKubernetes(
 		return ctrl.Result{}, err
 	}
 
-	if !isKubernetesUpgraded(nodes, k8sDistro.Version) {
+	if !isUpgraded(nodes) {
 		setInProgressCondition(upgradePlan, conditionType, "Control plane nodes are being upgraded")
 		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 	} else if controlPlaneOnlyCluster(nodeList) {
@@ -68,28 +112,12 @@ func (r *UpgradePlanReconciler) reconcileKubernetes(
 			return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 		}
 
-		setSuccessfulCondition(upgradePlan, conditionType, "All cluster nodes are upgraded")
-		return ctrl.Result{Requeue: true}, nil
+		return r.completeStage(ctx, upgradePlan, conditionType, "All cluster nodes are upgraded")
 	}
 
-	workerPlan := upgrade.KubernetesWorkerPlan(nameSuffix, k8sDistro.Version, drainWorker, identifierLabels)
-	if err = r.Get(ctx, client.ObjectKeyFromObject(workerPlan), workerPlan); err != nil {
-		if !errors.IsNotFound(err) {
-			return ctrl.Result{}, err
-		}
-
-		setInProgressCondition(upgradePlan, conditionType, "Worker nodes are being upgraded")
-		return ctrl.Result{}, r.createObject(ctx, upgradePlan, workerPlan)
-	}
-
-	nodes, err = findMatchingNodes(nodeList, workerPlan.Spec.NodeSelector)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-
-	if !isKubernetesUpgraded(nodes, k8sDistro.Version) {
-		setInProgressCondition(upgradePlan, conditionType, "Worker nodes are being upgraded")
-		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	result, finished, err := r.reconcileWorkerBatches(ctx, upgradePlan, conditionType, nodeList, workerPlan, isUpgraded)
+	if !finished || err != nil {
+		return result, err
 	}
 
 	allUpgraded, waitingFor, err := r.getK8sCoreComponentsUpgradeStatus(ctx, k8sDistro.CoreComponents)
@@ -103,8 +131,7 @@ func (r *UpgradePlanReconciler) reconcileKubernetes(
 		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 	}
 
-	setSuccessfulCondition(upgradePlan, conditionType, "All cluster nodes are upgraded")
-	return ctrl.Result{Requeue: true}, nil
+	return r.completeStage(ctx, upgradePlan, conditionType, "All cluster nodes are upgraded")
 }
 
 func (r *UpgradePlanReconciler) getK8sCoreComponentsUpgradeStatus(ctx context.Context, core []lifecyclev1alpha1.CoreComponent) (allUpgraded bool, waitingFor string, err error) {
@@ -181,16 +208,20 @@ func targetKubernetesDistribution(nodeList *corev1.NodeList, kubernetes *lifecyc
 		return nil, fmt.Errorf("unable to determine current kubernetes distribution due to empty node list")
 	}
 
-	kubeletVersion := nodeList.Items[0].Status.NodeInfo.KubeletVersion
+	return kubernetes.Distribution(nodeList.Items[0].Status.NodeInfo.KubeletVersion)
+}
 
-	switch {
-	case strings.Contains(kubeletVersion, "k3s"):
-		return &kubernetes.K3S, nil
-	case strings.Contains(kubeletVersion, "rke2"):
-		return &kubernetes.RKE2, nil
-	default:
-		return nil, fmt.Errorf("unsupported kubernetes distribution detected in version %s", kubeletVersion)
+// validateVersionSkew rejects unsupported Kubernetes version skews unless they have been explicitly allowed.
+func validateVersionSkew(
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	k8sDistro *lifecyclev1alpha1.KubernetesDistribution,
+	nodeList *corev1.NodeList,
+) error {
+	if upgradePlan.Spec.AllowUnsupportedVersionSkew {
+		return nil
 	}
+
+	return k8sDistro.ValidateVersionSkew(nodeList.Items)
 }
 
 func findMatchingNodes(nodeList *corev1.NodeList, nodeSelector *metav1.LabelSelector) ([]corev1.Node, error) {
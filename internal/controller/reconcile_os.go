@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
 	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
 	"github.com/suse-edge/upgrade-controller/internal/upgrade"
 	corev1 "k8s.io/api/core/v1"
@@ -28,62 +29,79 @@ func (r *UpgradePlanReconciler) reconcileOS(
 		return ctrl.Result{}, fmt.Errorf("generating OS upgrade secret: %w", err)
 	}
 
-	if err = r.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
-		if !errors.IsNotFound(err) {
-			return ctrl.Result{}, err
-		}
-
-		return ctrl.Result{}, r.createObject(ctx, upgradePlan, secret)
-	}
-
 	conditionType := lifecyclev1alpha1.OperatingSystemUpgradedCondition
 
 	drainControlPlane, drainWorker := parseDrainOptions(nodeList, upgradePlan)
 	controlPlanePlan := upgrade.OSControlPlanePlan(nameSuffix, releaseVersion, secret.Name, releaseOS, drainControlPlane, identifierLabels)
-	if err = r.Get(ctx, client.ObjectKeyFromObject(controlPlanePlan), controlPlanePlan); err != nil {
-		if !errors.IsNotFound(err) {
-			return ctrl.Result{}, err
-		}
-
-		setInProgressCondition(upgradePlan, conditionType, "Control plane nodes are being upgraded")
-		return ctrl.Result{}, r.createObject(ctx, upgradePlan, controlPlanePlan)
+	workerPlan := func(batch upgrade.WorkerBatch) *upgradecattlev1.Plan {
+		return upgrade.OSWorkerPlan(nameSuffix, releaseVersion, secret.Name, releaseOS, batch, drainWorker, identifierLabels)
+	}
+	isUpgraded := func(nodes []corev1.Node) bool {
+		return isOSUpgraded(nodes, releaseOS.PrettyName)
 	}
 
-	nodes, err := findMatchingNodes(nodeList, controlPlanePlan.Spec.NodeSelector)
+	setNodeProgress(upgradePlan, nodeList, isUpgraded)
+
+	planNames, err := stagePlanNames(upgradePlan, nodeList, controlPlanePlan, workerPlan)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	if !isOSUpgraded(nodes, releaseOS.PrettyName) {
-		setInProgressCondition(upgradePlan, conditionType, "Control plane nodes are being upgraded")
-		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
-	} else if controlPlaneOnlyCluster(nodeList) {
-		setSuccessfulCondition(upgradePlan, conditionType, "All cluster nodes are upgraded")
-		return ctrl.Result{Requeue: true}, nil
+	if err = r.reconcileNodeStatuses(ctx, upgradePlan, nodeList, releaseOS.PrettyName, planNames, isUpgraded); err != nil {
+		return ctrl.Result{}, err
 	}
 
-	workerPlan := upgrade.OSWorkerPlan(nameSuffix, releaseVersion, secret.Name, releaseOS, drainWorker, identifierLabels)
-	if err = r.Get(ctx, client.ObjectKeyFromObject(workerPlan), workerPlan); err != nil {
+	if r.failTimedOutNodes(upgradePlan, conditionType) {
+		return ctrl.Result{}, nil
+	}
+
+	if err = r.Get(ctx, client.ObjectKeyFromObject(controlPlanePlan), controlPlanePlan); err != nil {
 		if !errors.IsNotFound(err) {
 			return ctrl.Result{}, err
 		}
 
-		setInProgressCondition(upgradePlan, conditionType, "Worker nodes are being upgraded")
-		return ctrl.Result{}, r.createObject(ctx, upgradePlan, workerPlan)
+		if wait, err := awaitMaintenanceWindow(upgradePlan, conditionType); err != nil {
+			return ctrl.Result{}, err
+		} else if wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+
+		if result, finished, err := r.reconcileStageHooks(ctx, upgradePlan, conditionType, lifecyclev1alpha1.HookPhasePre); !finished || err != nil {
+			return result, err
+		}
+
+		if err = r.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+			if !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+
+			if err = r.createObject(ctx, upgradePlan, secret); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		setInProgressCondition(upgradePlan, conditionType, "Control plane nodes are being upgraded")
+		return ctrl.Result{}, r.createObject(ctx, upgradePlan, controlPlanePlan)
 	}
 
-	nodes, err = findMatchingNodes(nodeList, workerPlan.Spec.NodeSelector)
+	nodes, err := findMatchingNodes(nodeList, controlPlanePlan.Spec.NodeSelector)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	if !isOSUpgraded(nodes, releaseOS.PrettyName) {
-		setInProgressCondition(upgradePlan, conditionType, "Worker nodes are being upgraded")
+	if !isUpgraded(nodes) {
+		setInProgressCondition(upgradePlan, conditionType, "Control plane nodes are being upgraded")
 		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	} else if controlPlaneOnlyCluster(nodeList) {
+		return r.completeStage(ctx, upgradePlan, conditionType, "All cluster nodes are upgraded")
+	}
+
+	result, finished, err := r.reconcileWorkerBatches(ctx, upgradePlan, conditionType, nodeList, workerPlan, isUpgraded)
+	if !finished || err != nil {
+		return result, err
 	}
 
-	setSuccessfulCondition(upgradePlan, conditionType, "All cluster nodes are upgraded")
-	return ctrl.Result{Requeue: true}, nil
+	return r.completeStage(ctx, upgradePlan, conditionType, "All cluster nodes are upgraded")
 }
 
 func isOSUpgraded(nodes []corev1.Node, osPrettyName string) bool {
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// newReleaseHops returns the hops of an upgrade through the specified release versions.
+// Direct upgrades do not consist of any hops.
+func newReleaseHops(upgradePath []string) []lifecyclev1alpha1.ReleaseHop {
+	if len(upgradePath) < 2 {
+		return nil
+	}
+
+	hops := make([]lifecyclev1alpha1.ReleaseHop, 0, len(upgradePath))
+	for _, releaseVersion := range upgradePath {
+		hops = append(hops, lifecyclev1alpha1.ReleaseHop{ReleaseVersion: releaseVersion})
+	}
+
+	return hops
+}
+
+// currentHopIndex returns the index of the first hop of the current generation
+// which has not been completed yet or -1 if there is none.
+func currentHopIndex(upgradePlan *lifecyclev1alpha1.UpgradePlan) int {
+	if upgradePlan.Status.ObservedGeneration != upgradePlan.Generation {
+		return -1
+	}
+
+	for i, hop := range upgradePlan.Status.Hops {
+		if hop.CompletedAt == nil {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func currentHop(upgradePlan *lifecyclev1alpha1.UpgradePlan) *lifecyclev1alpha1.ReleaseHop {
+	if i := currentHopIndex(upgradePlan); i != -1 {
+		return &upgradePlan.Status.Hops[i]
+	}
+
+	return nil
+}
+
+// currentReleaseVersion returns the release version the cluster is currently being upgraded to.
+func currentReleaseVersion(upgradePlan *lifecyclev1alpha1.UpgradePlan) string {
+	if hop := currentHop(upgradePlan); hop != nil {
+		return hop.ReleaseVersion
+	}
+
+	return upgradePlan.Spec.ReleaseVersion
+}
+
+func completeCurrentHop(upgradePlan *lifecyclev1alpha1.UpgradePlan) {
+	if hop := currentHop(upgradePlan); hop != nil {
+		now := metav1.Now()
+		hop.CompletedAt = &now
+	}
+}
+
+// startNextHop records the completion of the current hop and starts the upgrade to the next release.
+// The next release is validated the same way as the first one, as the cluster may have changed in the meantime.
+func (r *UpgradePlanReconciler) startNextHop(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	nodeList *corev1.NodeList,
+) (ctrl.Result, error) {
+	i := currentHopIndex(upgradePlan)
+	hop, next := &upgradePlan.Status.Hops[i], &upgradePlan.Status.Hops[i+1]
+
+	release, err := r.retrieveReleaseManifest(ctx, upgradePlan, next.ReleaseVersion)
+	if err != nil {
+		if !errors.Is(err, errReleaseManifestNotFound) {
+			return ctrl.Result{}, fmt.Errorf("retrieving release manifest: %w", err)
+		}
+
+		return r.reconcileReleaseManifest(ctx, upgradePlan, next.ReleaseVersion)
+	}
+
+	setReleaseManifestRetrieved(upgradePlan, next.ReleaseVersion)
+
+	failure, err := r.reconcileReleaseValidation(ctx, upgradePlan, release, nodeList)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("validating release: %w", err)
+	} else if failure != nil {
+		if failure.preflight {
+			return ctrl.Result{RequeueAfter: preflightRetryInterval}, nil
+		}
+
+		setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhaseFailed, "", nodeList)
+		return ctrl.Result{}, nil
+	}
+
+	suffix, err := upgrade.GenerateSuffix()
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("generating suffix: %w", err)
+	}
+
+	if suffix == upgradePlan.Status.SUCNameSuffix {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	now := metav1.Now()
+	hop.CompletedAt = &now
+	upgradePlan.Status.LastSuccessfulReleaseVersion = hop.ReleaseVersion
+
+	r.Recorder.Eventf(upgradePlan, corev1.EventTypeNormal, "ReleaseHopCompleted",
+		"Upgrade to intermediate release %s completed, proceeding with release %s", hop.ReleaseVersion, next.ReleaseVersion)
+
+	setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhasePending, "", nodeList)
+	r.startRelease(upgradePlan, release, suffix)
+	return ctrl.Result{Requeue: true}, nil
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	helmcattlev1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewReleaseHops(t *testing.T) {
+	assert.Nil(t, newReleaseHops(nil))
+	assert.Nil(t, newReleaseHops([]string{"3.2.0"}))
+	assert.Equal(t, []lifecyclev1alpha1.ReleaseHop{
+		{ReleaseVersion: "3.1.0"},
+		{ReleaseVersion: "3.2.0"},
+	}, newReleaseHops([]string{"3.1.0", "3.2.0"}))
+}
+
+func TestCurrentReleaseVersion(t *testing.T) {
+	now := metav1.Now()
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       lifecyclev1alpha1.UpgradePlanSpec{ReleaseVersion: "3.2.0"},
+	}
+	assert.Equal(t, "3.2.0", currentReleaseVersion(plan))
+	assert.Nil(t, currentHop(plan))
+
+	plan.Status.ObservedGeneration = 2
+	plan.Status.Hops = []lifecyclev1alpha1.ReleaseHop{
+		{ReleaseVersion: "3.1.0", CompletedAt: &now},
+		{ReleaseVersion: "3.2.0"},
+	}
+	assert.Equal(t, "3.2.0", currentReleaseVersion(plan))
+	assert.Equal(t, 1, currentHopIndex(plan))
+
+	plan.Status.Hops[0].CompletedAt = nil
+	assert.Equal(t, "3.1.0", currentReleaseVersion(plan))
+	assert.Equal(t, 0, currentHopIndex(plan))
+
+	// Hops of previous generations are not taken into account.
+	plan.Generation = 3
+	assert.Equal(t, "3.2.0", currentReleaseVersion(plan))
+	assert.Equal(t, -1, currentHopIndex(plan))
+
+	plan.Status.ObservedGeneration = 3
+	completeCurrentHop(plan)
+	completeCurrentHop(plan)
+	assert.NotNil(t, plan.Status.Hops[0].CompletedAt)
+	assert.NotNil(t, plan.Status.Hops[1].CompletedAt)
+	assert.Nil(t, currentHop(plan))
+}
+
+func TestUpgradeHelmRelease_MultipleHops(t *testing.T) {
+	now := metav1.Now()
+	ctx := context.Background()
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default", Generation: 1},
+		Spec:       lifecyclev1alpha1.UpgradePlanSpec{ReleaseVersion: "3.2.0"},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			ObservedGeneration: 1,
+			Hops: []lifecyclev1alpha1.ReleaseHop{
+				{ReleaseVersion: "3.1.0", StartedAt: &now},
+				{ReleaseVersion: "3.2.0"},
+			},
+		},
+	}
+
+	newHelmRelease := func(name, version string) *helmrelease.Release {
+		return &helmrelease.Release{
+			Name:      name,
+			Namespace: name + "-system",
+			Info:      &helmrelease.Info{Status: helmrelease.StatusDeployed},
+			Chart:     &helmchart.Chart{Metadata: &helmchart.Metadata{Version: version}},
+		}
+	}
+
+	newHelmChart := func(name, version string) *helmcattlev1.HelmChart {
+		return &helmcattlev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   upgrade.KubeSystemNamespace,
+				Annotations: map[string]string{upgrade.ReleaseAnnotation: "3.1.0"},
+			},
+			Spec:   helmcattlev1.HelmChartSpec{Chart: name, Version: version},
+			Status: helmcattlev1.HelmChartStatus{JobName: "helm-install-" + name},
+		}
+	}
+
+	newCompletedJob := func(name string) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "helm-install-" + name, Namespace: upgrade.KubeSystemNamespace},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	// Both charts have been upgraded as part of the first hop.
+	r := newFakeReconciler(
+		newHelmChart("rancher", "2.9.1"), newCompletedJob("rancher"),
+		newHelmChart("longhorn", "1.7.1"), newCompletedJob("longhorn"),
+	)
+
+	rancher := &lifecyclev1alpha1.HelmChart{ReleaseName: "rancher", Name: "rancher", Version: "2.9.1"}
+	longhorn := &lifecyclev1alpha1.HelmChart{ReleaseName: "longhorn", Name: "longhorn", Version: "1.7.1"}
+
+	state, err := r.upgradeHelmRelease(ctx, plan, newHelmRelease("rancher", "2.9.1"), rancher, lifecyclev1alpha1.FailurePolicyContinue)
+	require.NoError(t, err)
+	assert.Equal(t, upgrade.ChartStateSucceeded, state)
+
+	completeCurrentHop(plan)
+	require.Equal(t, "3.2.0", currentReleaseVersion(plan))
+
+	// The second hop does not change the version of Rancher, but upgrades Longhorn.
+	longhorn.Version = "1.7.2"
+
+	state, err = r.upgradeHelmRelease(ctx, plan, newHelmRelease("rancher", "2.9.1"), rancher, lifecyclev1alpha1.FailurePolicyContinue)
+	require.NoError(t, err)
+	assert.Equal(t, upgrade.ChartStateVersionAlreadyInstalled, state)
+
+	state, err = r.upgradeHelmRelease(ctx, plan, newHelmRelease("longhorn", "1.7.1"), longhorn, lifecyclev1alpha1.FailurePolicyContinue)
+	require.NoError(t, err)
+	assert.Equal(t, upgrade.ChartStateInProgress, state)
+
+	chart := &helmcattlev1.HelmChart{}
+	require.NoError(t, r.Get(ctx, upgrade.ChartNamespacedName("longhorn"), chart))
+	assert.Equal(t, "1.7.2", chart.Spec.Version)
+	assert.Equal(t, "3.2.0", chart.Annotations[upgrade.ReleaseAnnotation])
+}
+
+func TestStartNextHop_Validation(t *testing.T) {
+	ctx := context.Background()
+	now := metav1.Now()
+
+	newPlan := func() *lifecyclev1alpha1.UpgradePlan {
+		return &lifecyclev1alpha1.UpgradePlan{
+			ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default", Generation: 1},
+			Spec:       lifecyclev1alpha1.UpgradePlanSpec{ReleaseVersion: "3.2.0"},
+			Status: lifecyclev1alpha1.UpgradePlanStatus{
+				ObservedGeneration: 1,
+				SUCNameSuffix:      "abcdef",
+				Hops: []lifecyclev1alpha1.ReleaseHop{
+					{ReleaseVersion: "3.1.0", StartedAt: &now},
+					{ReleaseVersion: "3.2.0"},
+				},
+			},
+		}
+	}
+
+	release := &lifecyclev1alpha1.ReleaseManifest{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-3-2-0", Namespace: "default"},
+		Spec: lifecyclev1alpha1.ReleaseManifestSpec{
+			ReleaseVersion: "3.2.0",
+			Components: lifecyclev1alpha1.Components{
+				Kubernetes: lifecyclev1alpha1.Kubernetes{
+					RKE2: lifecyclev1alpha1.KubernetesDistribution{Version: "v1.31.1+rke2r1"},
+				},
+			},
+		},
+	}
+
+	newNodeList := func(kubeletVersion string, ready corev1.ConditionStatus) *corev1.NodeList {
+		return &corev1.NodeList{
+			Items: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+					Status: corev1.NodeStatus{
+						NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+						Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("Unsupported version skew", func(t *testing.T) {
+		plan := newPlan()
+		r := newFakeReconciler(release)
+
+		// Nodes which joined during the first hop are still running an older version.
+		result, err := r.startNextHop(ctx, plan, newNodeList("v1.29.8+rke2r1", corev1.ConditionTrue))
+		require.NoError(t, err)
+		assert.True(t, result.IsZero())
+
+		assert.Equal(t, lifecyclev1alpha1.UpgradePhaseFailed, plan.Status.Phase)
+		assert.Nil(t, plan.Status.Hops[0].CompletedAt)
+		assert.Equal(t, "3.1.0", currentReleaseVersion(plan))
+
+		condition := meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.ValidationFailedCondition)
+		require.NotNil(t, condition)
+		assert.Equal(t, lifecyclev1alpha1.UnsupportedVersionSkewReason, condition.Reason)
+	})
+
+	t.Run("Failed pre-flight check", func(t *testing.T) {
+		plan := newPlan()
+		r := newFakeReconciler(release)
+
+		result, err := r.startNextHop(ctx, plan, newNodeList("v1.30.4+rke2r1", corev1.ConditionFalse))
+		require.NoError(t, err)
+		assert.Equal(t, preflightRetryInterval, result.RequeueAfter)
+
+		assert.NotEqual(t, lifecyclev1alpha1.UpgradePhaseFailed, plan.Status.Phase)
+		assert.Nil(t, plan.Status.Hops[0].CompletedAt)
+
+		condition := meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.ValidationFailedCondition)
+		require.NotNil(t, condition)
+		assert.Equal(t, lifecyclev1alpha1.NodesNotReadyReason, condition.Reason)
+
+		// The next release is started once the cluster has recovered.
+		result, err = r.startNextHop(ctx, plan, newNodeList("v1.30.4+rke2r1", corev1.ConditionTrue))
+		require.NoError(t, err)
+		assert.True(t, result.Requeue)
+
+		assert.NotNil(t, plan.Status.Hops[0].CompletedAt)
+		assert.Equal(t, "3.2.0", currentReleaseVersion(plan))
+		assert.Nil(t, meta.FindStatusCondition(plan.Status.Conditions, lifecyclev1alpha1.ValidationFailedCondition))
+	})
+}
@@ -1,21 +1,45 @@
 package controller
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
-	"github.com/suse-edge/upgrade-controller/internal/upgrade"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/util/retry"
+	"github.com/suse-edge/upgrade-controller/internal/source"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Initial interval at which failed release manifest retrievals are retried.
+	// Doubles with every failed attempt up to releaseManifestMaxRetryInterval.
+	releaseManifestRetryInterval    = 30 * time.Second
+	releaseManifestMaxRetryInterval = 10 * time.Minute
+
+	// Number of failed attempts after which the release manifest retrieval is given up.
+	releaseManifestMaxAttempts = 8
+
+	// Maximum length of the retrieval error included in the condition message.
+	releaseManifestMaxErrorLength = 1024
 )
 
 var errReleaseManifestNotFound = fmt.Errorf("release manifest not found")
 
-func (r *UpgradePlanReconciler) retrieveReleaseManifest(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) (*lifecyclev1alpha1.ReleaseManifest, error) {
+func (r *UpgradePlanReconciler) retrieveReleaseManifest(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	releaseVersion string,
+) (*lifecyclev1alpha1.ReleaseManifest, error) {
 	manifests := &lifecyclev1alpha1.ReleaseManifestList{}
 	listOpts := &client.ListOptions{
 		Namespace: upgradePlan.Namespace,
@@ -25,7 +49,7 @@ func (r *UpgradePlanReconciler) retrieveReleaseManifest(ctx context.Context, upg
 	}
 
 	for _, manifest := range manifests.Items {
-		if manifest.Spec.ReleaseVersion == upgradePlan.Spec.ReleaseVersion {
+		if manifest.Spec.ReleaseVersion == releaseVersion {
 			return &manifest, nil
 		}
 	}
@@ -33,19 +57,211 @@ func (r *UpgradePlanReconciler) retrieveReleaseManifest(ctx context.Context, upg
 	return nil, errReleaseManifestNotFound
 }
 
-func (r *UpgradePlanReconciler) createReleaseManifest(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) error {
-	labels := upgrade.PlanIdentifierLabels(upgradePlan.Name, upgradePlan.Namespace)
-	releaseManifest := upgrade.ContainerImage{
-		Name:    r.ReleaseManifestImage,
-		Version: strings.TrimPrefix(upgradePlan.Spec.ReleaseVersion, "v"),
+// reconcileReleaseManifest creates the manifest of the specified release from the release manifest source.
+// The outcome is reported under the ReleaseManifestRetrieved condition.
+// Failed retrievals are retried with an exponential backoff until releaseManifestMaxAttempts is reached.
+func (r *UpgradePlanReconciler) reconcileReleaseManifest(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	releaseVersion string,
+) (ctrl.Result, error) {
+	retrieval := upgradePlan.Status.ReleaseManifestRetrieval
+	if retrieval == nil || retrieval.ReleaseVersion != releaseVersion || retrieval.ObservedGeneration != upgradePlan.Generation {
+		retrieval = &lifecyclev1alpha1.ReleaseManifestRetrieval{
+			ReleaseVersion:     releaseVersion,
+			ObservedGeneration: upgradePlan.Generation,
+		}
+		upgradePlan.Status.ReleaseManifestRetrieval = retrieval
+	}
+
+	if retrieval.Attempts >= releaseManifestMaxAttempts {
+		// Retrieval has been given up. Updating the plan starts over.
+		return ctrl.Result{}, nil
+	}
+
+	if retrieval.LastAttemptTime != nil {
+		retryAt := retrieval.LastAttemptTime.Add(releaseManifestRetryBackoff(retrieval.Attempts))
+		if wait := time.Until(retryAt); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	reason, err := r.createReleaseManifest(ctx, upgradePlan, releaseVersion)
+	if err == nil {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	log.FromContext(ctx).Error(err, "Failed to retrieve release manifest", "version", releaseVersion, "attempt", retrieval.Attempts+1)
+
+	now := metav1.Now()
+	retrieval.Attempts++
+	retrieval.LastAttemptTime = &now
+
+	if reason == lifecyclev1alpha1.ReleaseManifestVerificationFailedReason {
+		// Retrying would not change the outcome. Updating the plan starts over.
+		retrieval.Attempts = releaseManifestMaxAttempts
+
+		message := fmt.Sprintf("Release manifest %s failed verification: %s", releaseVersion, errorExcerpt(err))
+
+		meta.SetStatusCondition(&upgradePlan.Status.Conditions, metav1.Condition{
+			Type:    lifecyclev1alpha1.ReleaseManifestRetrievedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: message,
+		})
+		setValidationFailedCondition(upgradePlan, reason, message)
+
+		r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "ReleaseManifestVerificationFailed", message)
+		return ctrl.Result{}, nil
+	}
+
+	result := ctrl.Result{RequeueAfter: releaseManifestRetryBackoff(retrieval.Attempts)}
+	message := fmt.Sprintf("Retrieving release manifest %s failed (attempt %d of %d): %s",
+		releaseVersion, retrieval.Attempts, releaseManifestMaxAttempts, errorExcerpt(err))
+
+	if retrieval.Attempts >= releaseManifestMaxAttempts {
+		result = ctrl.Result{}
+		message = fmt.Sprintf("Retrieving release manifest %s failed after %d attempts, giving up: %s",
+			releaseVersion, retrieval.Attempts, errorExcerpt(err))
+	}
+
+	condition := metav1.Condition{
+		Type:    lifecyclev1alpha1.ReleaseManifestRetrievedCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	if meta.SetStatusCondition(&upgradePlan.Status.Conditions, condition) {
+		r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "ReleaseManifestRetrievalFailed", message)
+	}
+
+	return result, nil
+}
+
+// setReleaseManifestRetrieved marks the retrieval of the release manifest as successful
+// once the manifest is present on the cluster. No-op unless the manifest had to be retrieved.
+func setReleaseManifestRetrieved(upgradePlan *lifecyclev1alpha1.UpgradePlan, releaseVersion string) {
+	if upgradePlan.Status.ReleaseManifestRetrieval == nil {
+		return
 	}
-	job, err := upgrade.ReleaseManifestInstallJob(releaseManifest, r.Kubectl, r.ServiceAccount, upgradePlan.Namespace, labels)
+
+	upgradePlan.Status.ReleaseManifestRetrieval = nil
+	meta.SetStatusCondition(&upgradePlan.Status.Conditions, metav1.Condition{
+		Type:    lifecyclev1alpha1.ReleaseManifestRetrievedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  lifecyclev1alpha1.ReleaseManifestRetrievedReason,
+		Message: fmt.Sprintf("Release manifest %s retrieved", releaseVersion),
+	})
+}
+
+// releaseManifestRetryBackoff returns the interval to wait for after the specified number of failed attempts.
+func releaseManifestRetryBackoff(attempts int32) time.Duration {
+	interval := releaseManifestRetryInterval
+	for i := int32(1); i < attempts && interval < releaseManifestMaxRetryInterval; i++ {
+		interval *= 2
+	}
+
+	return min(interval, releaseManifestMaxRetryInterval)
+}
+
+// errorExcerpt flattens the error into a single line no longer than releaseManifestMaxErrorLength.
+func errorExcerpt(err error) string {
+	message := strings.Join(strings.Fields(err.Error()), " ")
+	if len(message) > releaseManifestMaxErrorLength {
+		message = message[:releaseManifestMaxErrorLength] + "..."
+	}
+
+	return message
+}
+
+// createReleaseManifest fetches the manifest of the specified release and creates it in the namespace of the plan.
+// Returns the reason of the failure if the manifest could not be retrieved.
+func (r *UpgradePlanReconciler) createReleaseManifest(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	releaseVersion string,
+) (string, error) {
+	manifestSource := r.ReleaseManifestSource
+	if upgradePlan.Spec.ReleaseManifestSource != nil {
+		var err error
+		if manifestSource, err = source.New(upgradePlan.Spec.ReleaseManifestSource, r.SourceOptions); err != nil {
+			return lifecyclev1alpha1.ReleaseManifestFetchFailedReason, err
+		}
+	}
+
+	data, err := manifestSource.Fetch(ctx, upgradePlan.Namespace, releaseVersion, r.ReleaseManifestVerifier)
+	if err != nil {
+		if errors.Is(err, source.ErrVerificationFailed) {
+			return lifecyclev1alpha1.ReleaseManifestVerificationFailedReason, err
+		}
+
+		return lifecyclev1alpha1.ReleaseManifestFetchFailedReason, err
+	}
+
+	manifest, err := decodeReleaseManifest(data)
 	if err != nil {
-		return err
+		return lifecyclev1alpha1.ReleaseManifestParseFailedReason, err
+	}
+
+	if manifest.Spec.ReleaseVersion != releaseVersion {
+		return lifecyclev1alpha1.ReleaseManifestVersionMismatchReason,
+			fmt.Errorf("%s returned the manifest of release version %s", manifestSource, manifest.Spec.ReleaseVersion)
+	}
+
+	manifest.Namespace = upgradePlan.Namespace
+
+	if err = r.createObject(ctx, upgradePlan, manifest); err != nil && !apierrors.IsAlreadyExists(err) {
+		return lifecyclev1alpha1.ReleaseManifestApplyFailedReason, fmt.Errorf("creating release manifest: %w", err)
+	}
+
+	return "", nil
+}
+
+func decodeReleaseManifest(data []byte) (*lifecyclev1alpha1.ReleaseManifest, error) {
+	manifest := &lifecyclev1alpha1.ReleaseManifest{}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data)).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("decoding release manifest: %w", err)
+	}
+
+	expected := lifecyclev1alpha1.GroupVersion.WithKind("ReleaseManifest")
+	if gvk := manifest.GroupVersionKind(); gvk != expected {
+		return nil, fmt.Errorf("unexpected resource %s, expected %s", gvk, expected)
+	}
+
+	if manifest.Name == "" {
+		return nil, fmt.Errorf("release manifest name is empty")
+	}
+
+	return manifest, nil
+}
+
+// findUpgradePath returns the release versions the cluster has to be upgraded through
+// in order to reach the target release, ending with the target release version.
+// Unsupported upgrades result in an empty path and a validation failure. Does not modify the UpgradePlan.
+func (r *UpgradePlanReconciler) findUpgradePath(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	release *lifecyclev1alpha1.ReleaseManifest,
+) ([]string, *validationFailure, error) {
+	lastReleaseVersion := upgradePlan.Status.LastSuccessfulReleaseVersion
+
+	err := release.Spec.Compatibility.ValidateUpgradeFrom(lastReleaseVersion)
+	if err == nil {
+		return []string{release.Spec.ReleaseVersion}, nil, nil
+	}
+
+	manifests := &lifecyclev1alpha1.ReleaseManifestList{}
+	if err := r.List(ctx, manifests, client.InNamespace(upgradePlan.Namespace)); err != nil {
+		return nil, nil, fmt.Errorf("listing release manifests in cluster: %w", err)
+	}
+
+	path := lifecyclev1alpha1.UpgradePath(lastReleaseVersion, release, manifests.Items)
+	if len(path) == 0 {
+		return nil, &validationFailure{
+			reason:  lifecyclev1alpha1.UnsupportedUpgradePathReason,
+			message: err.Error(),
+		}, nil
 	}
 
-	// Retry the creation since a previously failed job could be in the process of deletion due to TTL
-	return retry.OnError(wait.Backoff{Steps: 5, Duration: 500 * time.Millisecond},
-		func(err error) bool { return true },
-		func() error { return r.createObject(ctx, upgradePlan, job) })
+	return path, nil, nil
 }
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeReleaseManifest(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{
+			name: "Valid manifest",
+			data: `apiVersion: lifecycle.suse.com/v1alpha1
+kind: ReleaseManifest
+metadata:
+  name: release-manifest-3-1-0
+spec:
+  releaseVersion: 3.1.0`,
+		},
+		{
+			name: "Unexpected kind",
+			data: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: release-manifest-3-1-0`,
+			expectedError: "unexpected resource /v1, Kind=ConfigMap, expected lifecycle.suse.com/v1alpha1, Kind=ReleaseManifest",
+		},
+		{
+			name: "Missing name",
+			data: `apiVersion: lifecycle.suse.com/v1alpha1
+kind: ReleaseManifest
+spec:
+  releaseVersion: 3.1.0`,
+			expectedError: "release manifest name is empty",
+		},
+		{
+			name:          "Invalid YAML",
+			data:          "releaseVersion: [",
+			expectedError: "decoding release manifest",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manifest, err := decodeReleaseManifest([]byte(test.data))
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "release-manifest-3-1-0", manifest.Name)
+			assert.Equal(t, "3.1.0", manifest.Spec.ReleaseVersion)
+		})
+	}
+}
+
+func TestReleaseManifestRetryBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, releaseManifestRetryBackoff(1))
+	assert.Equal(t, time.Minute, releaseManifestRetryBackoff(2))
+	assert.Equal(t, 8*time.Minute, releaseManifestRetryBackoff(5))
+	assert.Equal(t, 10*time.Minute, releaseManifestRetryBackoff(6))
+	assert.Equal(t, 10*time.Minute, releaseManifestRetryBackoff(releaseManifestMaxAttempts))
+}
+
+func TestErrorExcerpt(t *testing.T) {
+	err := errors.Join(errors.New("mirror.local: unexpected status code 503"), errors.New("registry.suse.com: unauthorized"))
+	assert.Equal(t, "mirror.local: unexpected status code 503 registry.suse.com: unauthorized", errorExcerpt(err))
+
+	excerpt := errorExcerpt(errors.New(strings.Repeat("a", 2*releaseManifestMaxErrorLength)))
+	assert.Equal(t, strings.Repeat("a", releaseManifestMaxErrorLength)+"...", excerpt)
+}
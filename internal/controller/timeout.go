@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	helmcattlev1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type period struct {
+	start, end time.Time
+}
+
+// excludedDuration returns how long the upgrade has been paused or waiting between the specified time and now.
+// Overlapping pause and wait periods are only counted once.
+func excludedDuration(plan *lifecyclev1alpha1.UpgradePlan, since, now time.Time) time.Duration {
+	var periods []period
+
+	add := func(start metav1.Time, end *metav1.Time) {
+		p := period{start: start.Time, end: now}
+		if end != nil && end.Time.Before(now) {
+			p.end = end.Time
+		}
+
+		if p.start.Before(since) {
+			p.start = since
+		}
+
+		if p.end.After(p.start) {
+			periods = append(periods, p)
+		}
+	}
+
+	for _, pause := range plan.Status.PausePeriods {
+		add(pause.PausedAt, pause.ResumedAt)
+	}
+
+	for _, wait := range plan.Status.WaitPeriods {
+		add(wait.StartedAt, wait.EndedAt)
+	}
+
+	slices.SortFunc(periods, func(a, b period) int {
+		return a.start.Compare(b.start)
+	})
+
+	var excluded time.Duration
+	var last time.Time
+
+	for _, p := range periods {
+		if p.start.Before(last) {
+			p.start = last
+		}
+
+		if p.end.After(p.start) {
+			excluded += p.end.Sub(p.start)
+			last = p.end
+		}
+	}
+
+	return excluded
+}
+
+// startWaitPeriod records that the upgrade is waiting for the specified reason, unless it already is.
+func startWaitPeriod(plan *lifecyclev1alpha1.UpgradePlan, reason string) {
+	if slices.ContainsFunc(plan.Status.WaitPeriods, func(wait lifecyclev1alpha1.WaitPeriod) bool {
+		return wait.Reason == reason && wait.EndedAt == nil
+	}) {
+		return
+	}
+
+	plan.Status.WaitPeriods = append(plan.Status.WaitPeriods, lifecyclev1alpha1.WaitPeriod{Reason: reason, StartedAt: metav1.Now()})
+}
+
+// endWaitPeriod records that the upgrade is no longer waiting for the specified reason.
+func endWaitPeriod(plan *lifecyclev1alpha1.UpgradePlan, reason string) {
+	for i := range plan.Status.WaitPeriods {
+		wait := &plan.Status.WaitPeriods[i]
+		if wait.Reason == reason && wait.EndedAt == nil {
+			now := metav1.Now()
+			wait.EndedAt = &now
+		}
+	}
+}
+
+// recordStageStart records the time at which the stage started, unless it has already been recorded.
+func recordStageStart(plan *lifecyclev1alpha1.UpgradePlan, conditionType string) {
+	if slices.ContainsFunc(plan.Status.Stages, func(stage lifecyclev1alpha1.StageStatus) bool {
+		return stage.Type == conditionType
+	}) {
+		return
+	}
+
+	plan.Status.Stages = append(plan.Status.Stages, lifecyclev1alpha1.StageStatus{Type: conditionType, StartedAt: metav1.Now()})
+}
+
+// stageDeadline returns the time at which the in-progress stage times out.
+// The deadline is postponed by the time the upgrade has been paused or waiting since the stage started.
+// The returned flag reports whether a stage timeout applies.
+func stageDeadline(plan *lifecyclev1alpha1.UpgradePlan, conditionType string, now time.Time) (time.Time, bool) {
+	timeouts := plan.Spec.Timeouts
+	if timeouts == nil || timeouts.Stage == nil {
+		return time.Time{}, false
+	}
+
+	condition := meta.FindStatusCondition(plan.Status.Conditions, conditionType)
+	if condition == nil || condition.Reason != lifecyclev1alpha1.UpgradeInProgress {
+		return time.Time{}, false
+	}
+
+	index := slices.IndexFunc(plan.Status.Stages, func(stage lifecyclev1alpha1.StageStatus) bool {
+		return stage.Type == conditionType
+	})
+	if index == -1 {
+		return time.Time{}, false
+	}
+
+	startedAt := plan.Status.Stages[index].StartedAt.Time
+	return startedAt.Add(timeouts.Stage.Duration + excludedDuration(plan, startedAt, now)), true
+}
+
+// stageTimedOut reports whether the stage has been in progress for longer than the configured stage timeout.
+func stageTimedOut(plan *lifecyclev1alpha1.UpgradePlan, conditionType string, now time.Time) bool {
+	deadline, ok := stageDeadline(plan, conditionType, now)
+	return ok && now.After(deadline)
+}
+
+// nodeUpgradeTimeoutMessage describes the nodes whose upgrade has exceeded the configured timeouts.
+// Returns an empty string if no timeout has been exceeded.
+func nodeUpgradeTimeoutMessage(plan *lifecyclev1alpha1.UpgradePlan, conditionType string, now time.Time) string {
+	if stageTimedOut(plan, conditionType, now) {
+		var pending []string
+		for _, node := range plan.Status.Nodes {
+			if node.State != lifecyclev1alpha1.UpgradeSucceeded {
+				pending = append(pending, node.Name)
+			}
+		}
+
+		// Stages waiting for their verification hooks once all nodes are upgraded time out separately.
+		if len(pending) != 0 {
+			return fmt.Sprintf("Upgrade timed out after %s, nodes %v are not upgraded", plan.Spec.Timeouts.Stage.Duration, pending)
+		}
+	}
+
+	timeouts := plan.Spec.Timeouts
+	if timeouts == nil || timeouts.Node == nil {
+		return ""
+	}
+
+	var stuck []string
+	for _, node := range plan.Status.Nodes {
+		if node.State == lifecyclev1alpha1.UpgradeSucceeded || node.StartedAt == nil {
+			continue
+		}
+
+		elapsed := now.Sub(node.StartedAt.Time) - excludedDuration(plan, node.StartedAt.Time, now)
+		if elapsed > timeouts.Node.Duration {
+			stuck = append(stuck, node.Name)
+		}
+	}
+
+	if len(stuck) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("Upgrade of nodes %v timed out after %s", stuck, timeouts.Node.Duration)
+}
+
+// failTimedOutNodes marks the node upgrade stage as failed if any of its timeouts has been exceeded.
+func (r *UpgradePlanReconciler) failTimedOutNodes(upgradePlan *lifecyclev1alpha1.UpgradePlan, conditionType string) bool {
+	message := nodeUpgradeTimeoutMessage(upgradePlan, conditionType, time.Now())
+	if message == "" {
+		return false
+	}
+
+	r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "UpgradeTimedOut", message)
+	setFailedCondition(upgradePlan, conditionType, message)
+	return true
+}
+
+// awaitHelmChart records that a chart upgrade is still ongoing.
+// The chart upgrade is marked as failed instead if the stage timeout has been exceeded.
+func (r *UpgradePlanReconciler) awaitHelmChart(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	conditionType string,
+	state upgrade.HelmChartState,
+	releaseName string,
+) (ctrl.Result, error) {
+	now := time.Now()
+
+	if !stageTimedOut(upgradePlan, conditionType, now) {
+		setInProgressCondition(upgradePlan, conditionType, state.FormattedMessage(releaseName))
+
+		if deadline, ok := stageDeadline(upgradePlan, conditionType, now); ok {
+			// Ensure that the timeout is enforced even if the job never finishes.
+			return ctrl.Result{RequeueAfter: time.Until(deadline) + time.Second}, nil
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	message := fmt.Sprintf("Chart %s upgrade timed out after %s", releaseName, upgradePlan.Spec.Timeouts.Stage.Duration)
+
+	chart := &helmcattlev1.HelmChart{}
+	if err := r.Get(ctx, upgrade.ChartNamespacedName(releaseName), chart); err == nil && chart.Status.JobName != "" {
+		message = fmt.Sprintf("%s, job %s/%s has not finished", message, upgrade.KubeSystemNamespace, chart.Status.JobName)
+	}
+
+	r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "UpgradeTimedOut", message)
+	setFailedCondition(upgradePlan, conditionType, message)
+	return ctrl.Result{Requeue: true}, nil
+}
@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeUpgradeTimeoutMessage(t *testing.T) {
+	now := time.Now()
+	startedAt := metav1.NewTime(now.Add(-time.Hour))
+
+	newPlan := func(timeouts *lifecyclev1alpha1.Timeouts, stageStarted time.Time) *lifecyclev1alpha1.UpgradePlan {
+		return &lifecyclev1alpha1.UpgradePlan{
+			Spec: lifecyclev1alpha1.UpgradePlanSpec{Timeouts: timeouts},
+			Status: lifecyclev1alpha1.UpgradePlanStatus{
+				Conditions: []metav1.Condition{
+					{Type: lifecyclev1alpha1.OperatingSystemUpgradedCondition, Reason: lifecyclev1alpha1.UpgradeInProgress},
+				},
+				Stages: []lifecyclev1alpha1.StageStatus{
+					{Type: lifecyclev1alpha1.OperatingSystemUpgradedCondition, StartedAt: metav1.NewTime(stageStarted)},
+				},
+				Nodes: []lifecyclev1alpha1.NodeStatus{
+					{Name: "cp-1", State: lifecyclev1alpha1.UpgradeSucceeded, StartedAt: &startedAt},
+					{Name: "worker-1", State: lifecyclev1alpha1.UpgradeInProgress, StartedAt: &startedAt},
+					{Name: "worker-2", State: lifecyclev1alpha1.UpgradePending},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		timeouts        *lifecyclev1alpha1.Timeouts
+		stageStarted    time.Time
+		expectedMessage string
+	}{
+		{
+			name:         "No timeouts",
+			stageStarted: now.Add(-24 * time.Hour),
+		},
+		{
+			name:         "Timeouts not exceeded",
+			timeouts:     &lifecyclev1alpha1.Timeouts{Stage: &metav1.Duration{Duration: 2 * time.Hour}, Node: &metav1.Duration{Duration: 90 * time.Minute}},
+			stageStarted: now.Add(-time.Hour),
+		},
+		{
+			name:            "Stage timeout exceeded",
+			timeouts:        &lifecyclev1alpha1.Timeouts{Stage: &metav1.Duration{Duration: 2 * time.Hour}},
+			stageStarted:    now.Add(-3 * time.Hour),
+			expectedMessage: "Upgrade timed out after 2h0m0s, nodes [worker-1 worker-2] are not upgraded",
+		},
+		{
+			name:            "Node timeout exceeded",
+			timeouts:        &lifecyclev1alpha1.Timeouts{Node: &metav1.Duration{Duration: 30 * time.Minute}},
+			stageStarted:    now.Add(-time.Hour),
+			expectedMessage: "Upgrade of nodes [worker-1] timed out after 30m0s",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan := newPlan(test.timeouts, test.stageStarted)
+			message := nodeUpgradeTimeoutMessage(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, now)
+			assert.Equal(t, test.expectedMessage, message)
+		})
+	}
+}
+
+func TestStageTimedOut(t *testing.T) {
+	now := time.Now()
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			Timeouts: &lifecyclev1alpha1.Timeouts{Stage: &metav1.Duration{Duration: time.Hour}},
+		},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			Conditions: []metav1.Condition{
+				{Type: "RancherUpgraded", Reason: lifecyclev1alpha1.UpgradeInProgress, LastTransitionTime: metav1.NewTime(now.Add(-time.Minute))},
+				{Type: "Metal3Upgraded", Reason: lifecyclev1alpha1.UpgradeInProgress, LastTransitionTime: metav1.NewTime(now.Add(-time.Minute))},
+				{Type: "ElementalUpgraded", Reason: lifecyclev1alpha1.UpgradePending, LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Hour))},
+				{Type: "NeuVectorUpgraded", Reason: lifecyclev1alpha1.UpgradeInProgress, LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Hour))},
+			},
+			// The stage start is not reset by the condition transitioning back to in progress after an error.
+			Stages: []lifecyclev1alpha1.StageStatus{
+				{Type: "RancherUpgraded", StartedAt: metav1.NewTime(now.Add(-2 * time.Hour))},
+				{Type: "Metal3Upgraded", StartedAt: metav1.NewTime(now.Add(-time.Minute))},
+				{Type: "ElementalUpgraded", StartedAt: metav1.NewTime(now.Add(-2 * time.Hour))},
+			},
+		},
+	}
+
+	assert.True(t, stageTimedOut(plan, "RancherUpgraded", now))
+	assert.False(t, stageTimedOut(plan, "Metal3Upgraded", now))
+	assert.False(t, stageTimedOut(plan, "ElementalUpgraded", now))
+	assert.False(t, stageTimedOut(plan, "NeuVectorUpgraded", now))
+	assert.False(t, stageTimedOut(plan, "KubeVirtUpgraded", now))
+}
+
+func TestSkipPendingUpgrades(t *testing.T) {
+	plan := &lifecyclev1alpha1.UpgradePlan{}
+	setFailedCondition(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, "timed out")
+	setPendingCondition(plan, lifecyclev1alpha1.KubernetesUpgradedCondition, "pending")
+	setPendingCondition(plan, "RancherUpgraded", "pending")
+
+	skipPendingUpgrades(plan)
+
+	for _, condition := range plan.Status.Conditions {
+		if condition.Type == lifecyclev1alpha1.OperatingSystemUpgradedCondition {
+			assert.Equal(t, lifecyclev1alpha1.UpgradeFailed, condition.Reason)
+			continue
+		}
+
+		assert.Equal(t, lifecyclev1alpha1.UpgradeSkipped, condition.Reason)
+	}
+}
+
+func TestTimeouts_PausedUpgrade(t *testing.T) {
+	now := time.Now()
+	startedAt := metav1.NewTime(now.Add(-3 * time.Hour))
+	pausedAt := metav1.NewTime(now.Add(-150 * time.Minute))
+	resumedAt := metav1.NewTime(now.Add(-time.Minute))
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			Timeouts: &lifecyclev1alpha1.Timeouts{
+				Stage: &metav1.Duration{Duration: time.Hour},
+				Node:  &metav1.Duration{Duration: time.Hour},
+			},
+		},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			Conditions: []metav1.Condition{
+				{Type: lifecyclev1alpha1.OperatingSystemUpgradedCondition, Reason: lifecyclev1alpha1.UpgradeInProgress},
+				{Type: "RancherUpgraded", Reason: lifecyclev1alpha1.UpgradeInProgress},
+			},
+			Stages: []lifecyclev1alpha1.StageStatus{
+				{Type: lifecyclev1alpha1.OperatingSystemUpgradedCondition, StartedAt: startedAt},
+				{Type: "RancherUpgraded", StartedAt: startedAt},
+			},
+			Nodes: []lifecyclev1alpha1.NodeStatus{
+				{Name: "worker-1", State: lifecyclev1alpha1.UpgradeInProgress, StartedAt: &startedAt},
+			},
+			PausePeriods: []lifecyclev1alpha1.PausePeriod{
+				{PausedAt: pausedAt, ResumedAt: &resumedAt},
+			},
+		},
+	}
+
+	// The upgrade has only been running for 31 minutes outside of the pause.
+	assert.Equal(t, 149*time.Minute, excludedDuration(plan, startedAt.Time, now))
+	assert.False(t, stageTimedOut(plan, "RancherUpgraded", now))
+	assert.Empty(t, nodeUpgradeTimeoutMessage(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, now))
+
+	deadline, ok := stageDeadline(plan, "RancherUpgraded", now)
+	assert.True(t, ok)
+	assert.Equal(t, startedAt.Add(209*time.Minute), deadline)
+
+	// Pauses before the stage started are not taken into account.
+	plan.Status.PausePeriods[0].PausedAt = metav1.NewTime(now.Add(-5 * time.Hour))
+	plan.Status.PausePeriods[0].ResumedAt = &metav1.Time{Time: now.Add(-4 * time.Hour)}
+	assert.True(t, stageTimedOut(plan, "RancherUpgraded", now))
+
+	// Ongoing pauses are counted up to now.
+	plan.Status.PausePeriods = append(plan.Status.PausePeriods, lifecyclev1alpha1.PausePeriod{PausedAt: metav1.NewTime(now.Add(-2 * time.Hour))})
+	assert.Equal(t, 2*time.Hour, excludedDuration(plan, startedAt.Time, now))
+	assert.False(t, stageTimedOut(plan, "RancherUpgraded", now))
+}
+
+func TestPauseAndResumeUpgrade_PausePeriods(t *testing.T) {
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+	}
+
+	r := newFakeReconciler()
+	ctx := context.Background()
+
+	require.NoError(t, r.pauseUpgrade(ctx, plan))
+	require.Len(t, plan.Status.PausePeriods, 1)
+	assert.Nil(t, plan.Status.PausePeriods[0].ResumedAt)
+
+	// Reconciling a paused plan does not record another period.
+	require.NoError(t, r.pauseUpgrade(ctx, plan))
+	require.Len(t, plan.Status.PausePeriods, 1)
+
+	require.NoError(t, r.resumeUpgrade(ctx, plan))
+	require.Len(t, plan.Status.PausePeriods, 1)
+	assert.NotNil(t, plan.Status.PausePeriods[0].ResumedAt)
+}
+
+func TestTimeouts_WaitPeriods(t *testing.T) {
+	now := time.Now()
+	startedAt := metav1.NewTime(now.Add(-3 * time.Hour))
+
+	plan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			Timeouts: &lifecyclev1alpha1.Timeouts{Stage: &metav1.Duration{Duration: time.Hour}},
+		},
+		Status: lifecyclev1alpha1.UpgradePlanStatus{
+			Conditions: []metav1.Condition{
+				{Type: lifecyclev1alpha1.OperatingSystemUpgradedCondition, Reason: lifecyclev1alpha1.UpgradeInProgress},
+			},
+			Stages: []lifecyclev1alpha1.StageStatus{
+				{Type: lifecyclev1alpha1.OperatingSystemUpgradedCondition, StartedAt: startedAt},
+			},
+			WaitPeriods: []lifecyclev1alpha1.WaitPeriod{
+				{
+					Reason:    lifecyclev1alpha1.OutsideMaintenanceWindowReason,
+					StartedAt: metav1.NewTime(now.Add(-150 * time.Minute)),
+					EndedAt:   &metav1.Time{Time: now.Add(-90 * time.Minute)},
+				},
+			},
+		},
+	}
+
+	// The stage has been running for two hours outside of the maintenance window wait.
+	assert.True(t, stageTimedOut(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, now))
+
+	// Soaking the canary nodes past the deadline does not time the stage out.
+	plan.Status.WaitPeriods = append(plan.Status.WaitPeriods, lifecyclev1alpha1.WaitPeriod{
+		Reason:    lifecyclev1alpha1.CanarySoakReason,
+		StartedAt: metav1.NewTime(now.Add(-90 * time.Minute)),
+	})
+	assert.Equal(t, 150*time.Minute, excludedDuration(plan, startedAt.Time, now))
+	assert.False(t, stageTimedOut(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, now))
+
+	// The stage times out once the soak ends.
+	plan.Status.WaitPeriods[1].EndedAt = &metav1.Time{Time: now.Add(-40 * time.Minute)}
+	assert.True(t, stageTimedOut(plan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, now))
+
+	// Pauses overlapping with waits are only excluded once.
+	plan.Status.PausePeriods = []lifecyclev1alpha1.PausePeriod{
+		{PausedAt: metav1.NewTime(now.Add(-50 * time.Minute)), ResumedAt: &metav1.Time{Time: now.Add(-30 * time.Minute)}},
+	}
+	assert.Equal(t, 120*time.Minute, excludedDuration(plan, startedAt.Time, now))
+}
+
+func TestWaitPeriods(t *testing.T) {
+	plan := &lifecyclev1alpha1.UpgradePlan{}
+
+	startWaitPeriod(plan, lifecyclev1alpha1.CanarySoakReason)
+	startWaitPeriod(plan, lifecyclev1alpha1.CanarySoakReason)
+	require.Len(t, plan.Status.WaitPeriods, 1)
+	assert.Nil(t, plan.Status.WaitPeriods[0].EndedAt)
+
+	// Ending an inactive wait has no effect.
+	endWaitPeriod(plan, lifecyclev1alpha1.OutsideMaintenanceWindowReason)
+	assert.Nil(t, plan.Status.WaitPeriods[0].EndedAt)
+
+	endWaitPeriod(plan, lifecyclev1alpha1.CanarySoakReason)
+	assert.NotNil(t, plan.Status.WaitPeriods[0].EndedAt)
+
+	startWaitPeriod(plan, lifecyclev1alpha1.CanarySoakReason)
+	require.Len(t, plan.Status.WaitPeriods, 2)
+}
+
+func TestRecordStageStart(t *testing.T) {
+	plan := &lifecyclev1alpha1.UpgradePlan{}
+	conditionType := lifecyclev1alpha1.KubernetesUpgradedCondition
+
+	setInProgressCondition(plan, conditionType, "in progress")
+	require.Len(t, plan.Status.Stages, 1)
+	startedAt := plan.Status.Stages[0].StartedAt
+
+	// Transient errors do not restart the stage.
+	setErrorCondition(plan, conditionType, "error")
+	setInProgressCondition(plan, conditionType, "in progress")
+	require.Len(t, plan.Status.Stages, 1)
+	assert.Equal(t, conditionType, plan.Status.Stages[0].Type)
+	assert.Equal(t, startedAt, plan.Status.Stages[0].StartedAt)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/k3s-io/helm-controller/pkg/controllers/chart"
 	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
 	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/source"
 	"github.com/suse-edge/upgrade-controller/internal/upgrade"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -51,22 +52,30 @@ var errUpgradeInProgress = errors.New("upgrade is currently in progress")
 // UpgradePlanReconciler reconciles a UpgradePlan object
 type UpgradePlanReconciler struct {
 	client.Client
-	Scheme               *runtime.Scheme
-	Recorder             record.EventRecorder
-	ServiceAccount       string
-	ReleaseManifestImage string
-	Kubectl              upgrade.ContainerImage
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ReleaseManifestSource is used to retrieve release manifests which are not present on the cluster,
+	// unless the UpgradePlan specifies a source of its own.
+	ReleaseManifestSource source.Source
+	// SourceOptions are used to create the release manifest sources specified in UpgradePlans.
+	SourceOptions source.Options
+	// ReleaseManifestVerifier verifies the signatures of the retrieved release manifests.
+	// Signatures are not verified if unset.
+	ReleaseManifestVerifier *source.Verifier
 }
 
 // +kubebuilder:rbac:groups=lifecycle.suse.com,resources=upgradeplans,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=lifecycle.suse.com,resources=upgradeplans/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=lifecycle.suse.com,resources=upgradeplans/finalizers,verbs=update
-// +kubebuilder:rbac:groups=upgrade.cattle.io,resources=plans,verbs=create;list;get;watch;delete
+// +kubebuilder:rbac:groups=upgrade.cattle.io,resources=plans,verbs=create;list;get;watch;update;delete
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=watch;list
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;delete;create;watch
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;statefulsets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
-// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get
 // +kubebuilder:rbac:groups=helm.cattle.io,resources=helmcharts,verbs=get;update;list;watch;create
 // +kubebuilder:rbac:groups=helm.cattle.io,resources=helmcharts/status,verbs=get
@@ -99,6 +108,8 @@ func (r *UpgradePlanReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			return ctrl.Result{}, err
 		}
 
+		deleteMetrics(plan)
+
 		controllerutil.RemoveFinalizer(plan, lifecyclev1alpha1.UpgradePlanFinalizer)
 		return ctrl.Result{}, r.Update(ctx, plan)
 	}
@@ -110,13 +121,27 @@ func (r *UpgradePlanReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{Requeue: true}, r.Update(ctx, plan)
 	}
 
+	previousConditions := slices.Clone(plan.Status.Conditions)
+
 	result, err := r.reconcileNormal(ctx, plan)
 
 	// Attempt to update the plan status before returning.
-	return result, errors.Join(err, r.Status().Update(ctx, plan))
+	return result, errors.Join(err, r.updateStatus(ctx, plan, previousConditions))
 }
 
-func (r *UpgradePlanReconciler) reconcileDelete(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) error {
+// updateStatus persists the status of the upgrade plan and records its metrics.
+// Metrics are only recorded once the status has been written, so that the same transitions
+// are not observed again when the reconciliation is retried due to a failed update.
+func (r *UpgradePlanReconciler) updateStatus(ctx context.Context, plan *lifecyclev1alpha1.UpgradePlan, previousConditions []metav1.Condition) error {
+	if err := r.Status().Update(ctx, plan); err != nil {
+		return err
+	}
+
+	recordMetrics(plan, previousConditions)
+	return nil
+}
+
+func sucListOptions(upgradePlan *lifecyclev1alpha1.UpgradePlan) *client.ListOptions {
 	labelSelector := client.MatchingLabels{
 		upgrade.PlanNameLabel:      upgradePlan.Name,
 		upgrade.PlanNamespaceLabel: upgradePlan.Namespace,
@@ -127,6 +152,12 @@ func (r *UpgradePlanReconciler) reconcileDelete(ctx context.Context, upgradePlan
 	}
 	labelSelector.ApplyToList(listOpts)
 
+	return listOpts
+}
+
+func (r *UpgradePlanReconciler) reconcileDelete(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) error {
+	listOpts := sucListOptions(upgradePlan)
+
 	sucPlans := &upgradecattlev1.PlanList{}
 	if err := r.List(ctx, sucPlans, listOpts); err != nil {
 		return fmt.Errorf("retrieving SUC plans: %w", err)
@@ -153,19 +184,23 @@ func (r *UpgradePlanReconciler) reconcileDelete(ctx context.Context, upgradePlan
 		}
 	}
 
-	return nil
+	return r.deleteHookJobs(ctx, upgradePlan)
 }
 
 func (r *UpgradePlanReconciler) reconcileNormal(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan) (ctrl.Result, error) {
-	release, err := r.retrieveReleaseManifest(ctx, upgradePlan)
+	releaseVersion := currentReleaseVersion(upgradePlan)
+
+	release, err := r.retrieveReleaseManifest(ctx, upgradePlan, releaseVersion)
 	if err != nil {
 		if !errors.Is(err, errReleaseManifestNotFound) {
 			return ctrl.Result{}, fmt.Errorf("retrieving release manifest: %w", err)
 		}
 
-		return ctrl.Result{}, r.createReleaseManifest(ctx, upgradePlan)
+		return r.reconcileReleaseManifest(ctx, upgradePlan, releaseVersion)
 	}
 
+	setReleaseManifestRetrieved(upgradePlan, releaseVersion)
+
 	nodeList := &corev1.NodeList{}
 	if err := r.List(ctx, nodeList); err != nil {
 		return ctrl.Result{}, fmt.Errorf("listing nodes: %w", err)
@@ -173,18 +208,49 @@ func (r *UpgradePlanReconciler) reconcileNormal(ctx context.Context, upgradePlan
 
 	supportedArchitectures := lifecyclev1alpha1.SupportedArchitectures(release.Spec.Components.OperatingSystem.SupportedArchs)
 	if unsupportedNodes := findUnsupportedNodes(nodeList, supportedArchitectures); len(unsupportedNodes) > 0 {
-		condition := metav1.Condition{
-			Type:    lifecyclev1alpha1.ValidationFailedCondition,
-			Status:  metav1.ConditionTrue,
-			Reason:  lifecyclev1alpha1.UnsupportedArchitectureReason,
-			Message: fmt.Sprintf("One or more cluster nodes are running on unsupported architecture: %s", unsupportedNodes),
-		}
-		meta.SetStatusCondition(&upgradePlan.Status.Conditions, condition)
-
+		setValidationFailedCondition(upgradePlan, lifecyclev1alpha1.UnsupportedArchitectureReason,
+			fmt.Sprintf("One or more cluster nodes are running on unsupported architecture: %s", unsupportedNodes))
 		return ctrl.Result{}, nil
 	}
 
+	if upgradePlan.Spec.DryRun {
+		return r.reconcilePreview(ctx, upgradePlan, release, nodeList)
+	}
+
+	if upgradePlan.IsPaused() {
+		return ctrl.Result{}, r.pauseUpgrade(ctx, upgradePlan)
+	} else if meta.FindStatusCondition(upgradePlan.Status.Conditions, lifecyclev1alpha1.PausedCondition) != nil {
+		if err = r.resumeUpgrade(ctx, upgradePlan); err != nil {
+			return ctrl.Result{}, fmt.Errorf("resuming upgrade: %w", err)
+		}
+	}
+
 	if upgradePlan.Status.ObservedGeneration != upgradePlan.Generation {
+		upgradePath, failure, err := r.findUpgradePath(ctx, upgradePlan, release)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("finding upgrade path: %w", err)
+		} else if failure != nil {
+			setValidationFailedCondition(upgradePlan, failure.reason, failure.message)
+			return ctrl.Result{}, nil
+		}
+
+		// Multi-hop upgrades start with the first intermediate release.
+		if upgradePath[0] != release.Spec.ReleaseVersion {
+			if release, err = r.retrieveReleaseManifest(ctx, upgradePlan, upgradePath[0]); err != nil {
+				return ctrl.Result{}, fmt.Errorf("retrieving release manifest: %w", err)
+			}
+		}
+
+		failure, err = r.reconcileReleaseValidation(ctx, upgradePlan, release, nodeList)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("validating release: %w", err)
+		} else if failure != nil {
+			if failure.preflight {
+				return ctrl.Result{RequeueAfter: preflightRetryInterval}, nil
+			}
+			return ctrl.Result{}, nil
+		}
+
 		suffix, err := upgrade.GenerateSuffix()
 		if err != nil {
 			return ctrl.Result{}, fmt.Errorf("generating suffix: %w", err)
@@ -196,39 +262,134 @@ func (r *UpgradePlanReconciler) reconcileNormal(ctx context.Context, upgradePlan
 			return ctrl.Result{Requeue: true}, nil
 		}
 
-		upgradePlan.Status.SUCNameSuffix = suffix
-		upgradePlan.Status.ObservedGeneration = upgradePlan.Generation
-
-		setPendingCondition(upgradePlan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, upgradePendingMessage("OS"))
-		setPendingCondition(upgradePlan, lifecyclev1alpha1.KubernetesUpgradedCondition, upgradePendingMessage("Kubernetes"))
-
-		for _, chart := range release.Spec.Components.Workloads.Helm {
-			setPendingCondition(upgradePlan, lifecyclev1alpha1.GetChartConditionType(chart.PrettyName), upgradePendingMessage(chart.PrettyName))
-		}
+		upgradePlan.Status.Hops = newReleaseHops(upgradePath)
+		resetProgress(upgradePlan, nodeList)
 
+		r.startRelease(upgradePlan, release, suffix)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	if wait, err := r.reconcileMaintenanceWindow(ctx, upgradePlan); err != nil {
+		return ctrl.Result{}, fmt.Errorf("reconciling maintenance window: %w", err)
+	} else if wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	logger := log.FromContext(ctx)
+
 	switch {
-	case !meta.IsStatusConditionTrue(upgradePlan.Status.Conditions, lifecyclev1alpha1.OperatingSystemUpgradedCondition):
+	case isUpgradeFailed(upgradePlan, lifecyclev1alpha1.OperatingSystemUpgradedCondition),
+		isUpgradeFailed(upgradePlan, lifecyclev1alpha1.KubernetesUpgradedCondition):
+		logger.Info("Upgrade halted due to failed node upgrade")
+		skipPendingUpgrades(upgradePlan)
+		setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhaseFailed, "", nodeList)
+		return ctrl.Result{}, nil
+	case !isNodeUpgradeFinished(upgradePlan, lifecyclev1alpha1.OperatingSystemUpgradedCondition):
+		setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhaseOSUpgrade, "OS", nodeList)
 		return r.reconcileOS(ctx, upgradePlan, release.Spec.ReleaseVersion, &release.Spec.Components.OperatingSystem, nodeList)
-	case !meta.IsStatusConditionTrue(upgradePlan.Status.Conditions, lifecyclev1alpha1.KubernetesUpgradedCondition):
+	case !isNodeUpgradeFinished(upgradePlan, lifecyclev1alpha1.KubernetesUpgradedCondition):
+		setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhaseKubernetesUpgrade, "Kubernetes", nodeList)
 		return r.reconcileKubernetes(ctx, upgradePlan, &release.Spec.Components.Kubernetes, nodeList)
 	}
 
+	var failedCharts []string
 	for _, chart := range release.Spec.Components.Workloads.Helm {
-		if !isHelmUpgradeFinished(upgradePlan, lifecyclev1alpha1.GetChartConditionType(chart.PrettyName)) {
+		conditionType := lifecyclev1alpha1.GetChartConditionType(chart.PrettyName)
+
+		if !isHelmUpgradeFinished(upgradePlan, conditionType) {
+			setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhaseWorkloadUpgrade, chart.PrettyName, nodeList)
 			return r.reconcileHelmChart(ctx, upgradePlan, &chart)
 		}
+
+		if !isUpgradeFailed(upgradePlan, conditionType) {
+			continue
+		}
+
+		failedCharts = append(failedCharts, chart.PrettyName)
+
+		if helmFailurePolicy(upgradePlan, &chart) == lifecyclev1alpha1.FailurePolicyHalt {
+			logger.Info("Upgrade halted", "chart", chart.PrettyName)
+			skipPendingUpgrades(upgradePlan)
+			setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhaseFailed, chart.PrettyName, nodeList)
+			r.Recorder.Eventf(upgradePlan, corev1.EventTypeWarning, "UpgradeHalted",
+				"Upgrade halted as chart '%s' failed to upgrade", chart.PrettyName)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	if len(failedCharts) != 0 {
+		logger.Info("Upgrade completed with failures", "charts", failedCharts)
+		setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhaseFailed, "", nodeList)
+		return ctrl.Result{}, nil
+	}
+
+	if hop := currentHop(upgradePlan); hop != nil && hop.ReleaseVersion != upgradePlan.Spec.ReleaseVersion {
+		logger.Info("Intermediate release upgrade completed", "release", hop.ReleaseVersion)
+		return r.startNextHop(ctx, upgradePlan, nodeList)
 	}
 
-	logger := log.FromContext(ctx)
 	logger.Info("Upgrade completed")
+	completeCurrentHop(upgradePlan)
+	setPhase(upgradePlan, lifecyclev1alpha1.UpgradePhaseSucceeded, "", nodeList)
 
 	upgradePlan.Status.LastSuccessfulReleaseVersion = release.Spec.ReleaseVersion
 	return ctrl.Result{}, nil
 }
 
+// startRelease resets the upgrade state in order to start the upgrade to the specified release.
+func (r *UpgradePlanReconciler) startRelease(upgradePlan *lifecyclev1alpha1.UpgradePlan, release *lifecyclev1alpha1.ReleaseManifest, suffix string) {
+	upgradePlan.Status.SUCNameSuffix = suffix
+	upgradePlan.Status.ObservedGeneration = upgradePlan.Generation
+	upgradePlan.Status.Preview = nil
+	upgradePlan.Status.NodeGroups = nil
+	upgradePlan.Status.Nodes = nil
+	upgradePlan.Status.PausePeriods = nil
+	upgradePlan.Status.WaitPeriods = nil
+	upgradePlan.Status.Stages = nil
+
+	meta.RemoveStatusCondition(&upgradePlan.Status.Conditions, lifecyclev1alpha1.DryRunCondition)
+	meta.RemoveStatusCondition(&upgradePlan.Status.Conditions, lifecyclev1alpha1.MaintenanceWindowCondition)
+
+	components := upgradePlan.Spec.Components
+
+	if components.IncludesOS() {
+		setPendingCondition(upgradePlan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, upgradePendingMessage("OS"))
+	} else {
+		setSkippedCondition(upgradePlan, lifecyclev1alpha1.OperatingSystemUpgradedCondition, upgradeExcludedMessage("OS"))
+	}
+
+	if components.IncludesKubernetes() {
+		setPendingCondition(upgradePlan, lifecyclev1alpha1.KubernetesUpgradedCondition, upgradePendingMessage("Kubernetes"))
+	} else {
+		setSkippedCondition(upgradePlan, lifecyclev1alpha1.KubernetesUpgradedCondition, upgradeExcludedMessage("Kubernetes"))
+	}
+
+	for _, chart := range release.Spec.Components.Workloads.Helm {
+		conditionType := lifecyclev1alpha1.GetChartConditionType(chart.PrettyName)
+
+		if components.IncludesChart(&chart) {
+			setPendingCondition(upgradePlan, conditionType, upgradePendingMessage(chart.PrettyName))
+		} else {
+			setSkippedCondition(upgradePlan, conditionType, upgradeExcludedMessage(chart.PrettyName))
+		}
+	}
+
+	if release.Spec.Compatibility.IsDeprecated(release.Spec.ReleaseVersion) {
+		r.Recorder.Eventf(upgradePlan, corev1.EventTypeWarning, "DeprecatedRelease",
+			"Release %s is deprecated", release.Spec.ReleaseVersion)
+	}
+
+	if unknownCharts := findUnknownCharts(components, release.Spec.Components.Workloads.Helm); len(unknownCharts) != 0 {
+		r.Recorder.Eventf(upgradePlan, corev1.EventTypeWarning, "UnknownCharts",
+			"Charts %v are not part of release %s", unknownCharts, release.Spec.ReleaseVersion)
+	}
+
+	if hop := currentHop(upgradePlan); hop != nil {
+		now := metav1.Now()
+		hop.StartedAt = &now
+	}
+}
+
 func (r *UpgradePlanReconciler) createObject(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan, object client.Object) error {
 	// Extract the kind first since the data of the object pointer is modified during creation.
 	kind := object.GetObjectKind().GroupVersionKind().Kind
@@ -243,6 +404,17 @@ func (r *UpgradePlanReconciler) createObject(ctx context.Context, upgradePlan *l
 	return nil
 }
 
+func isNodeUpgradeFinished(plan *lifecyclev1alpha1.UpgradePlan, conditionType string) bool {
+	condition := meta.FindStatusCondition(plan.Status.Conditions, conditionType)
+
+	if condition == nil {
+		return false
+	}
+
+	return condition.Status == metav1.ConditionTrue ||
+		(condition.Status == metav1.ConditionFalse && condition.Reason == lifecyclev1alpha1.UpgradeSkipped)
+}
+
 func isHelmUpgradeFinished(plan *lifecyclev1alpha1.UpgradePlan, conditionType string) bool {
 	condition := meta.FindStatusCondition(plan.Status.Conditions, conditionType)
 
@@ -260,7 +432,24 @@ func isHelmUpgradeFinished(plan *lifecyclev1alpha1.UpgradePlan, conditionType st
 	return false
 }
 
-func parseDrainOptions(nodeList *corev1.NodeList, plan *lifecyclev1alpha1.UpgradePlan) (drainControlPlane bool, drainWorker bool) {
+func isUpgradeFailed(plan *lifecyclev1alpha1.UpgradePlan, conditionType string) bool {
+	condition := meta.FindStatusCondition(plan.Status.Conditions, conditionType)
+
+	return condition != nil &&
+		condition.Status == metav1.ConditionFalse && condition.Reason == lifecyclev1alpha1.UpgradeFailed
+}
+
+// skipPendingUpgrades marks all components which have not started upgrading as skipped
+// once the upgrade has been stopped due to a failure.
+func skipPendingUpgrades(plan *lifecyclev1alpha1.UpgradePlan) {
+	for _, condition := range plan.Status.Conditions {
+		if condition.Reason == lifecyclev1alpha1.UpgradePending {
+			setSkippedCondition(plan, condition.Type, "Upgrade skipped due to a previous failure")
+		}
+	}
+}
+
+func parseDrainOptions(nodeList *corev1.NodeList, plan *lifecyclev1alpha1.UpgradePlan) (drainControlPlane, drainWorker *upgradecattlev1.DrainSpec) {
 	var controlPlaneCounter, workerCounter int
 	for _, node := range nodeList.Items {
 		if node.Labels[upgrade.ControlPlaneLabel] != "true" {
@@ -270,33 +459,44 @@ func parseDrainOptions(nodeList *corev1.NodeList, plan *lifecyclev1alpha1.Upgrad
 		}
 	}
 
-	switch {
-	case controlPlaneCounter > 1 && workerCounter <= 1:
-		drainControlPlane = true
-		drainWorker = false
-	case controlPlaneCounter == 1 && workerCounter > 1:
-		drainControlPlane = false
-		drainWorker = true
-	case controlPlaneCounter <= 1 && workerCounter <= 1:
-		drainControlPlane = false
-		drainWorker = false
-	default:
-		drainControlPlane = true
-		drainWorker = true
-	}
+	// By default, nodes are only drained if there are other nodes with the same role
+	enableControlPlane := controlPlaneCounter > 1
+	enableWorker := workerCounter > 1
 
 	if plan.Spec.DisableDrain != nil {
 		// If user has explicitly disabled control-plane drains
 		if plan.Spec.DisableDrain.ControlPlane {
-			drainControlPlane = false
+			enableControlPlane = false
 		}
 
 		// If user has explicitly disabled worker drains
 		if plan.Spec.DisableDrain.Worker {
-			drainWorker = false
+			enableWorker = false
 		}
 	}
 
+	var controlPlaneOptions, workerOptions *lifecyclev1alpha1.DrainOptions
+	if plan.Spec.Drain != nil {
+		controlPlaneOptions = plan.Spec.Drain.ControlPlane
+		workerOptions = plan.Spec.Drain.Worker
+	}
+
+	if controlPlaneOptions != nil && controlPlaneOptions.Enabled != nil {
+		enableControlPlane = *controlPlaneOptions.Enabled
+	}
+
+	if workerOptions != nil && workerOptions.Enabled != nil {
+		enableWorker = *workerOptions.Enabled
+	}
+
+	if enableControlPlane {
+		drainControlPlane = upgrade.DrainSpec(controlPlaneOptions)
+	}
+
+	if enableWorker {
+		drainWorker = upgrade.DrainSpec(workerOptions)
+	}
+
 	return drainControlPlane, drainWorker
 }
 
@@ -304,6 +504,29 @@ func upgradePendingMessage(component string) string {
 	return fmt.Sprintf("%s upgrade is not yet started", component)
 }
 
+func upgradeExcludedMessage(component string) string {
+	return fmt.Sprintf("%s upgrade is excluded by the upgrade plan", component)
+}
+
+// findUnknownCharts returns the selected charts which do not match any of the release charts.
+func findUnknownCharts(components *lifecyclev1alpha1.ComponentSelection, charts []lifecyclev1alpha1.HelmChart) []string {
+	if components == nil {
+		return nil
+	}
+
+	var unknown []string
+
+	for _, name := range slices.Concat(components.Charts, components.ExcludeCharts) {
+		if !slices.ContainsFunc(charts, func(chart lifecyclev1alpha1.HelmChart) bool {
+			return name == chart.PrettyName || name == chart.ReleaseName
+		}) {
+			unknown = append(unknown, name)
+		}
+	}
+
+	return unknown
+}
+
 type setCondition func(plan *lifecyclev1alpha1.UpgradePlan, conditionType string, message string)
 
 func setPendingCondition(plan *lifecyclev1alpha1.UpgradePlan, conditionType, message string) {
@@ -311,6 +534,11 @@ func setPendingCondition(plan *lifecyclev1alpha1.UpgradePlan, conditionType, mes
 	meta.SetStatusCondition(&plan.Status.Conditions, condition)
 }
 
+func setValidationFailedCondition(plan *lifecyclev1alpha1.UpgradePlan, reason, message string) {
+	condition := metav1.Condition{Type: lifecyclev1alpha1.ValidationFailedCondition, Status: metav1.ConditionTrue, Reason: reason, Message: message}
+	meta.SetStatusCondition(&plan.Status.Conditions, condition)
+}
+
 func setErrorCondition(plan *lifecyclev1alpha1.UpgradePlan, conditionType, message string) {
 	condition := metav1.Condition{Type: conditionType, Status: metav1.ConditionUnknown, Reason: lifecyclev1alpha1.UpgradeError, Message: message}
 	meta.SetStatusCondition(&plan.Status.Conditions, condition)
@@ -319,6 +547,7 @@ func setErrorCondition(plan *lifecyclev1alpha1.UpgradePlan, conditionType, messa
 func setInProgressCondition(plan *lifecyclev1alpha1.UpgradePlan, conditionType, message string) {
 	condition := metav1.Condition{Type: conditionType, Status: metav1.ConditionFalse, Reason: lifecyclev1alpha1.UpgradeInProgress, Message: message}
 	meta.SetStatusCondition(&plan.Status.Conditions, condition)
+	recordStageStart(plan, conditionType)
 }
 
 func setSuccessfulCondition(plan *lifecyclev1alpha1.UpgradePlan, conditionType, message string) {
@@ -392,7 +621,7 @@ func (r *UpgradePlanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&lifecyclev1alpha1.UpgradePlan{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&lifecyclev1alpha1.UpgradePlan{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
 		Watches(&upgradecattlev1.Plan{}, handler.EnqueueRequestsFromMapFunc(r.findUpgradePlanFromLabel), builder.WithPredicates(predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
 				return false
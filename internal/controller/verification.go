@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// Interval at which failed verification hooks are retried.
+	verificationRetryInterval = 30 * time.Second
+
+	endpointRequestTimeout = 10 * time.Second
+)
+
+var (
+	endpointClient = &http.Client{Timeout: endpointRequestTimeout}
+
+	insecureEndpointClient = &http.Client{
+		Timeout: endpointRequestTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+)
+
+// completeStage marks the stage as successful once all verification hooks pass
+// and all post-upgrade hooks have finished.
+// The stage is marked as failed instead if the hooks do not pass within the stage timeout.
+func (r *UpgradePlanReconciler) completeStage(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	conditionType string,
+	message string,
+) (ctrl.Result, error) {
+	failing, err := r.runVerificationHooks(ctx, upgradePlan.Spec.Verification)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("running verification hooks: %w", err)
+	}
+
+	if failing == "" {
+		if result, finished, err := r.reconcileStageHooks(ctx, upgradePlan, conditionType, lifecyclev1alpha1.HookPhasePost); !finished || err != nil {
+			return result, err
+		}
+
+		setSuccessfulCondition(upgradePlan, conditionType, message)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if stageTimedOut(upgradePlan, conditionType, time.Now()) {
+		message = fmt.Sprintf("Verification did not pass within %s: %s", upgradePlan.Spec.Timeouts.Stage.Duration, failing)
+
+		r.Recorder.Event(upgradePlan, corev1.EventTypeWarning, "UpgradeTimedOut", message)
+		setFailedCondition(upgradePlan, conditionType, message)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	setInProgressCondition(upgradePlan, conditionType, fmt.Sprintf("Waiting for verification: %s", failing))
+	return ctrl.Result{RequeueAfter: verificationRetryInterval}, nil
+}
+
+// runVerificationHooks returns a description of the first failing verification hook, if any.
+func (r *UpgradePlanReconciler) runVerificationHooks(ctx context.Context, hooks *lifecyclev1alpha1.VerificationHooks) (string, error) {
+	if hooks == nil {
+		return "", nil
+	}
+
+	for _, workload := range hooks.Workloads {
+		rolledOut, err := r.isWorkloadRolledOut(ctx, &workload)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Sprintf("%s %s/%s not found", workload.Kind, workload.Namespace, workload.Name), nil
+			}
+
+			return "", fmt.Errorf("verifying %s %s/%s: %w", workload.Kind, workload.Namespace, workload.Name, err)
+		}
+
+		if !rolledOut {
+			return fmt.Sprintf("%s %s/%s is not rolled out", workload.Kind, workload.Namespace, workload.Name), nil
+		}
+	}
+
+	for _, endpoint := range hooks.Endpoints {
+		if err := checkEndpoint(ctx, &endpoint); err != nil {
+			return fmt.Sprintf("endpoint %s: %s", endpoint.URL, err), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (r *UpgradePlanReconciler) isWorkloadRolledOut(ctx context.Context, workload *lifecyclev1alpha1.WorkloadReference) (bool, error) {
+	key := types.NamespacedName{Name: workload.Name, Namespace: workload.Namespace}
+
+	switch workload.Kind {
+	case lifecyclev1alpha1.WorkloadKindDeployment:
+		deployment := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, deployment); err != nil {
+			return false, err
+		}
+
+		return isDeploymentRolledOut(deployment), nil
+	case lifecyclev1alpha1.WorkloadKindDaemonSet:
+		daemonSet := &appsv1.DaemonSet{}
+		if err := r.Get(ctx, key, daemonSet); err != nil {
+			return false, err
+		}
+
+		return isDaemonSetRolledOut(daemonSet), nil
+	case lifecyclev1alpha1.WorkloadKindStatefulSet:
+		statefulSet := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, key, statefulSet); err != nil {
+			return false, err
+		}
+
+		return isStatefulSetRolledOut(statefulSet), nil
+	default:
+		return false, fmt.Errorf("unsupported workload kind: %s", workload.Kind)
+	}
+}
+
+func isDeploymentRolledOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	status := deployment.Status
+
+	return status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas == replicas &&
+		status.Replicas == status.UpdatedReplicas &&
+		status.AvailableReplicas == status.UpdatedReplicas
+}
+
+func isDaemonSetRolledOut(daemonSet *appsv1.DaemonSet) bool {
+	status := daemonSet.Status
+
+	return status.ObservedGeneration >= daemonSet.Generation &&
+		status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+		status.NumberAvailable == status.DesiredNumberScheduled
+}
+
+func isStatefulSetRolledOut(statefulSet *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+
+	status := statefulSet.Status
+
+	return status.ObservedGeneration >= statefulSet.Generation &&
+		status.ReadyReplicas == replicas &&
+		status.UpdatedReplicas == replicas &&
+		status.CurrentRevision == status.UpdateRevision
+}
+
+func checkEndpoint(ctx context.Context, endpoint *lifecyclev1alpha1.HTTPEndpoint) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.URL, nil)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+
+	httpClient := endpointClient
+	if endpoint.InsecureSkipTLSVerify {
+		httpClient = insecureEndpointClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestIsDeploymentRolledOut(t *testing.T) {
+	deployment := func(generation, observedGeneration int64, replicas, updated, total, available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(replicas)},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: observedGeneration,
+				UpdatedReplicas:    updated,
+				Replicas:           total,
+				AvailableReplicas:  available,
+			},
+		}
+	}
+
+	assert.True(t, isDeploymentRolledOut(deployment(2, 2, 3, 3, 3, 3)))
+	assert.False(t, isDeploymentRolledOut(deployment(2, 1, 3, 3, 3, 3)), "spec change not yet observed")
+	assert.False(t, isDeploymentRolledOut(deployment(2, 2, 3, 2, 3, 3)), "replicas not yet updated")
+	assert.False(t, isDeploymentRolledOut(deployment(2, 2, 3, 3, 4, 3)), "old replicas still running")
+	assert.False(t, isDeploymentRolledOut(deployment(2, 2, 3, 3, 3, 2)), "updated replicas not yet available")
+}
+
+func TestIsDaemonSetRolledOut(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     1,
+			DesiredNumberScheduled: 3,
+			UpdatedNumberScheduled: 3,
+			NumberAvailable:        3,
+		},
+	}
+	assert.True(t, isDaemonSetRolledOut(daemonSet))
+
+	daemonSet.Status.NumberAvailable = 2
+	assert.False(t, isDaemonSetRolledOut(daemonSet))
+}
+
+func TestIsStatefulSetRolledOut(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](2)},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 1,
+			ReadyReplicas:      2,
+			UpdatedReplicas:    2,
+			CurrentRevision:    "web-abc",
+			UpdateRevision:     "web-abc",
+		},
+	}
+	assert.True(t, isStatefulSetRolledOut(statefulSet))
+
+	statefulSet.Status.UpdateRevision = "web-def"
+	assert.False(t, isStatefulSetRolledOut(statefulSet))
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer tlsServer.Close()
+
+	ctx := context.Background()
+
+	assert.NoError(t, checkEndpoint(ctx, &lifecyclev1alpha1.HTTPEndpoint{URL: server.URL + "/healthz"}))
+	assert.EqualError(t, checkEndpoint(ctx, &lifecyclev1alpha1.HTTPEndpoint{URL: server.URL + "/ready"}), "unexpected status code 503")
+
+	// Self-signed certificates are only accepted if TLS verification is skipped.
+	assert.Error(t, checkEndpoint(ctx, &lifecyclev1alpha1.HTTPEndpoint{URL: tlsServer.URL}))
+	assert.NoError(t, checkEndpoint(ctx, &lifecyclev1alpha1.HTTPEndpoint{URL: tlsServer.URL, InsecureSkipTLSVerify: true}))
+}
@@ -0,0 +1,352 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// workerBatches splits the worker nodes into the batches which are upgraded by separate SUC Plans.
+// Batches are returned in order of execution: the canary batch, the node groups
+// and finally the batch containing all remaining worker nodes.
+// The canary batch is limited to the specified hostnames if any, so that it is not changed by nodes
+// joining the cluster once its plans have been created. The other batches reflect the current worker nodes.
+func workerBatches(upgradePlan *lifecyclev1alpha1.UpgradePlan, nodeList *corev1.NodeList, canary []string) ([]upgrade.WorkerBatch, error) {
+	workers := upgradePlan.Spec.Workers
+	if workers == nil && len(upgradePlan.Spec.NodeGroups) == 0 {
+		return []upgrade.WorkerBatch{upgrade.DefaultWorkerBatch}, nil
+	}
+
+	var workerNodes []corev1.Node
+	for _, node := range nodeList.Items {
+		if node.Labels[upgrade.ControlPlaneLabel] == "true" {
+			continue
+		}
+
+		// Batches target their nodes by hostname.
+		if _, ok := node.Labels[corev1.LabelHostname]; !ok {
+			return nil, fmt.Errorf("worker node %s is missing the %s label", node.Name, corev1.LabelHostname)
+		}
+
+		workerNodes = append(workerNodes, node)
+	}
+
+	concurrency := int64(1)
+	if workers != nil && workers.Concurrency != nil {
+		scaled, err := intstr.GetScaledValueFromIntOrPercent(workers.Concurrency, len(workerNodes), true)
+		if err != nil {
+			return nil, fmt.Errorf("parsing worker concurrency: %w", err)
+		}
+
+		concurrency = int64(max(scaled, 1))
+	}
+
+	var batches []upgrade.WorkerBatch
+	var assigned []string
+
+	if workers != nil && workers.Canary != nil {
+		hostnames := canary
+		if len(hostnames) == 0 {
+			var err error
+			if hostnames, err = canaryHostnames(workers.Canary, workerNodes); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(hostnames) != 0 {
+			batches = append(batches, upgrade.WorkerBatch{
+				Name:        upgrade.CanaryBatch,
+				Hostnames:   hostnames,
+				Concurrency: concurrency,
+			})
+			assigned = append(assigned, hostnames...)
+		}
+	}
+
+	for _, group := range upgradePlan.Spec.NodeGroups {
+		hostnames, err := nodeGroupHostnames(&group, workerNodes, assigned)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(hostnames) == 0 {
+			continue
+		}
+
+		batches = append(batches, upgrade.WorkerBatch{
+			Name:        group.Name,
+			Hostnames:   hostnames,
+			Concurrency: concurrency,
+		})
+		assigned = append(assigned, hostnames...)
+	}
+
+	if !slices.ContainsFunc(workerNodes, func(node corev1.Node) bool {
+		return !slices.Contains(assigned, nodeHostname(&node))
+	}) {
+		return batches, nil
+	}
+
+	slices.Sort(assigned)
+	return append(batches, upgrade.WorkerBatch{ExcludedHostnames: assigned, Concurrency: concurrency}), nil
+}
+
+// nodeGroupHostnames returns the worker nodes matching the node group which are not part of any previous batch.
+func nodeGroupHostnames(group *lifecyclev1alpha1.NodeGroup, workerNodes []corev1.Node, assigned []string) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(group.NodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing node selector of group %s: %w", group.Name, err)
+	}
+
+	var hostnames []string
+	for _, node := range workerNodes {
+		hostname := nodeHostname(&node)
+		if selector.Matches(labels.Set(node.Labels)) && !slices.Contains(assigned, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	slices.Sort(hostnames)
+	return hostnames, nil
+}
+
+func canaryHostnames(canary *lifecyclev1alpha1.CanaryBatch, workerNodes []corev1.Node) ([]string, error) {
+	selector := labels.Everything()
+
+	if canary.NodeSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(canary.NodeSelector); err != nil {
+			return nil, fmt.Errorf("parsing canary node selector: %w", err)
+		}
+	}
+
+	var hostnames []string
+	for _, node := range workerNodes {
+		if selector.Matches(labels.Set(node.Labels)) {
+			hostnames = append(hostnames, nodeHostname(&node))
+		}
+	}
+
+	// Sort in order to select the same nodes on every reconciliation.
+	slices.Sort(hostnames)
+
+	if canary.NodeSelector == nil {
+		hostnames = hostnames[:min(canary.Count, len(hostnames))]
+	}
+
+	return hostnames, nil
+}
+
+func nodeHostname(node *corev1.Node) string {
+	return node.Labels[corev1.LabelHostname]
+}
+
+// canaryPlanHostnames returns the hostnames targeted by the plan of the canary batch, if it has been created.
+func (r *UpgradePlanReconciler) canaryPlanHostnames(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	workerPlan func(batch upgrade.WorkerBatch) *upgradecattlev1.Plan,
+) ([]string, error) {
+	if upgradePlan.Spec.Workers == nil || upgradePlan.Spec.Workers.Canary == nil {
+		return nil, nil
+	}
+
+	plan := workerPlan(upgrade.WorkerBatch{Name: upgrade.CanaryBatch})
+	if err := r.Get(ctx, client.ObjectKeyFromObject(plan), plan); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	return upgrade.BatchHostnames(plan.Spec.NodeSelector), nil
+}
+
+// setNodeGroupCondition records the upgrade progress of the node group upgraded by the specified batch.
+// Existing conditions are only overwritten if requested.
+func setNodeGroupCondition(
+	plan *lifecyclev1alpha1.UpgradePlan,
+	batch upgrade.WorkerBatch,
+	conditionType string,
+	status metav1.ConditionStatus,
+	reason, message string,
+	overwrite bool,
+) {
+	index := slices.IndexFunc(plan.Status.NodeGroups, func(group lifecyclev1alpha1.NodeGroupStatus) bool {
+		return group.Name == batch.Name
+	})
+	if index == -1 {
+		plan.Status.NodeGroups = append(plan.Status.NodeGroups, lifecyclev1alpha1.NodeGroupStatus{Name: batch.Name})
+		index = len(plan.Status.NodeGroups) - 1
+	}
+
+	group := &plan.Status.NodeGroups[index]
+	group.Nodes = batch.Hostnames
+
+	if !overwrite && meta.FindStatusCondition(group.Conditions, conditionType) != nil {
+		return
+	}
+
+	condition := metav1.Condition{Type: conditionType, Status: status, Reason: reason, Message: message}
+	meta.SetStatusCondition(&group.Conditions, condition)
+}
+
+func workerBatchMessage(batch upgrade.WorkerBatch) string {
+	if batch.Name == upgrade.CanaryBatch {
+		return "Canary worker nodes are being upgraded"
+	}
+
+	return "Worker nodes are being upgraded"
+}
+
+// reconcileWorkerBatches upgrades the worker batches in order.
+// The returned flag reports whether all batches have been upgraded.
+func (r *UpgradePlanReconciler) reconcileWorkerBatches(
+	ctx context.Context,
+	upgradePlan *lifecyclev1alpha1.UpgradePlan,
+	conditionType string,
+	nodeList *corev1.NodeList,
+	workerPlan func(batch upgrade.WorkerBatch) *upgradecattlev1.Plan,
+	isUpgraded func(nodes []corev1.Node) bool,
+) (ctrl.Result, bool, error) {
+	canary, err := r.canaryPlanHostnames(ctx, upgradePlan, workerPlan)
+	if err != nil {
+		return ctrl.Result{}, false, fmt.Errorf("retrieving canary nodes: %w", err)
+	}
+
+	batches, err := workerBatches(upgradePlan, nodeList, canary)
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+
+	isNodeGroup := func(batch upgrade.WorkerBatch) bool {
+		return slices.ContainsFunc(upgradePlan.Spec.NodeGroups, func(group lifecyclev1alpha1.NodeGroup) bool {
+			return group.Name == batch.Name
+		})
+	}
+
+	for _, batch := range batches {
+		if isNodeGroup(batch) {
+			setNodeGroupCondition(upgradePlan, batch, conditionType, metav1.ConditionUnknown, lifecyclev1alpha1.UpgradePending, "Upgrade is not yet started", false)
+		}
+	}
+
+	for _, batch := range batches {
+		desired := workerPlan(batch)
+		message := workerBatchMessage(batch)
+		nodeGroup := isNodeGroup(batch)
+
+		plan := &upgradecattlev1.Plan{}
+		if err = r.Get(ctx, client.ObjectKeyFromObject(desired), plan); err != nil {
+			if !errors.IsNotFound(err) {
+				return ctrl.Result{}, false, err
+			}
+
+			if wait, err := awaitMaintenanceWindow(upgradePlan, conditionType); err != nil {
+				return ctrl.Result{}, false, err
+			} else if wait > 0 {
+				return ctrl.Result{RequeueAfter: wait}, false, nil
+			}
+
+			if nodeGroup {
+				setNodeGroupCondition(upgradePlan, batch, conditionType, metav1.ConditionFalse, lifecyclev1alpha1.UpgradeInProgress, "Nodes are being upgraded", true)
+			}
+
+			setInProgressCondition(upgradePlan, conditionType, message)
+			return ctrl.Result{}, false, r.createObject(ctx, upgradePlan, desired)
+		}
+
+		// Worker nodes which joined the cluster since the plan was created are assigned to their batch.
+		if !equality.Semantic.DeepEqual(plan.Spec.NodeSelector, desired.Spec.NodeSelector) {
+			plan.Spec.NodeSelector = desired.Spec.NodeSelector
+			if err = r.Update(ctx, plan); err != nil {
+				return ctrl.Result{}, false, fmt.Errorf("updating node selector of plan %s: %w", plan.Name, err)
+			}
+		}
+
+		nodes, err := findMatchingNodes(nodeList, plan.Spec.NodeSelector)
+		if err != nil {
+			return ctrl.Result{}, false, err
+		}
+
+		if !isUpgraded(nodes) {
+			if _, ok := plan.Annotations[upgrade.CanarySoakStartAnnotation]; ok {
+				// Canary nodes have become unhealthy while soaking, restart the soak period once they recover.
+				delete(plan.Annotations, upgrade.CanarySoakStartAnnotation)
+				if err = r.Update(ctx, plan); err != nil {
+					return ctrl.Result{}, false, fmt.Errorf("resetting canary soak period: %w", err)
+				}
+
+				endWaitPeriod(upgradePlan, lifecyclev1alpha1.CanarySoakReason)
+			}
+
+			if nodeGroup {
+				setNodeGroupCondition(upgradePlan, batch, conditionType, metav1.ConditionFalse, lifecyclev1alpha1.UpgradeInProgress, "Nodes are being upgraded", true)
+			}
+
+			setInProgressCondition(upgradePlan, conditionType, message)
+			return ctrl.Result{RequeueAfter: 1 * time.Minute}, false, nil
+		}
+
+		if nodeGroup {
+			setNodeGroupCondition(upgradePlan, batch, conditionType, metav1.ConditionTrue, lifecyclev1alpha1.UpgradeSucceeded, "All nodes in the group are upgraded", true)
+		}
+
+		if batch.Name == upgrade.CanaryBatch {
+			if wait, err := r.soakCanary(ctx, upgradePlan, plan); err != nil {
+				return ctrl.Result{}, false, err
+			} else if wait > 0 {
+				// Time spent soaking does not count towards the stage timeout.
+				startWaitPeriod(upgradePlan, lifecyclev1alpha1.CanarySoakReason)
+
+				soakEnd := time.Now().Add(wait).UTC().Format(time.RFC3339)
+				setInProgressCondition(upgradePlan, conditionType, fmt.Sprintf("Canary worker nodes are upgraded, soaking until %s", soakEnd))
+				return ctrl.Result{RequeueAfter: wait}, false, nil
+			}
+
+			endWaitPeriod(upgradePlan, lifecyclev1alpha1.CanarySoakReason)
+		}
+	}
+
+	return ctrl.Result{}, true, nil
+}
+
+// soakCanary returns the remaining time of the soak period of the canary batch.
+func (r *UpgradePlanReconciler) soakCanary(ctx context.Context, upgradePlan *lifecyclev1alpha1.UpgradePlan, plan *upgradecattlev1.Plan) (time.Duration, error) {
+	soakDuration := upgradePlan.Spec.Workers.Canary.SoakDuration.Duration
+	if soakDuration <= 0 {
+		return 0, nil
+	}
+
+	value, ok := plan.Annotations[upgrade.CanarySoakStartAnnotation]
+	if !ok {
+		if plan.Annotations == nil {
+			plan.Annotations = map[string]string{}
+		}
+		plan.Annotations[upgrade.CanarySoakStartAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+		if err := r.Update(ctx, plan); err != nil {
+			return 0, fmt.Errorf("recording canary soak start: %w", err)
+		}
+
+		return soakDuration, nil
+	}
+
+	soakStart, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing canary soak start: %w", err)
+	}
+
+	return max(time.Until(soakStart.Add(soakDuration)), 0), nil
+}
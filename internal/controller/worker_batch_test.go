@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestWorkerBatches(t *testing.T) {
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{upgrade.ControlPlaneLabel: "true"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-3", Labels: map[string]string{"zone": "b", corev1.LabelHostname: "worker-3"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"zone": "a", corev1.LabelHostname: "worker-1"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{"zone": "a", corev1.LabelHostname: "host-2"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-4", Labels: map[string]string{"zone": "b", corev1.LabelHostname: "worker-4"}}},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		workers         *lifecyclev1alpha1.WorkerUpgrade
+		expectedBatches []upgrade.WorkerBatch
+	}{
+		{
+			name:            "Default",
+			expectedBatches: []upgrade.WorkerBatch{upgrade.DefaultWorkerBatch},
+		},
+		{
+			name: "Absolute concurrency",
+			workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Concurrency: ptr.To(intstr.FromInt32(3)),
+			},
+			expectedBatches: []upgrade.WorkerBatch{
+				{Concurrency: 3},
+			},
+		},
+		{
+			name: "Percentage concurrency is rounded up",
+			workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Concurrency: ptr.To(intstr.FromString("30%")),
+			},
+			expectedBatches: []upgrade.WorkerBatch{
+				{Concurrency: 2},
+			},
+		},
+		{
+			name: "Canary by count",
+			workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Canary: &lifecyclev1alpha1.CanaryBatch{Count: 2},
+			},
+			expectedBatches: []upgrade.WorkerBatch{
+				{Name: upgrade.CanaryBatch, Hostnames: []string{"host-2", "worker-1"}, Concurrency: 1},
+				{ExcludedHostnames: []string{"host-2", "worker-1"}, Concurrency: 1},
+			},
+		},
+		{
+			name: "Canary by node selector",
+			workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Concurrency: ptr.To(intstr.FromInt32(2)),
+				Canary: &lifecyclev1alpha1.CanaryBatch{
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "b"}},
+				},
+			},
+			expectedBatches: []upgrade.WorkerBatch{
+				{Name: upgrade.CanaryBatch, Hostnames: []string{"worker-3", "worker-4"}, Concurrency: 2},
+				{ExcludedHostnames: []string{"worker-3", "worker-4"}, Concurrency: 2},
+			},
+		},
+		{
+			name: "Canary covering all workers",
+			workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Canary: &lifecyclev1alpha1.CanaryBatch{Count: 10},
+			},
+			expectedBatches: []upgrade.WorkerBatch{
+				{Name: upgrade.CanaryBatch, Hostnames: []string{"host-2", "worker-1", "worker-3", "worker-4"}, Concurrency: 1},
+			},
+		},
+		{
+			name: "Canary not matching any workers",
+			workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Canary: &lifecyclev1alpha1.CanaryBatch{
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "c"}},
+				},
+			},
+			expectedBatches: []upgrade.WorkerBatch{
+				{Concurrency: 1},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+				Spec: lifecyclev1alpha1.UpgradePlanSpec{Workers: test.workers},
+			}
+
+			batches, err := workerBatches(upgradePlan, nodeList, nil)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedBatches, batches)
+		})
+	}
+}
+
+func TestWorkerBatches_NodeGroups(t *testing.T) {
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{upgrade.ControlPlaneLabel: "true", "rack": "1"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"rack": "1", corev1.LabelHostname: "worker-1"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{"rack": "1", corev1.LabelHostname: "worker-2"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-3", Labels: map[string]string{"rack": "2", corev1.LabelHostname: "worker-3"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-4", Labels: map[string]string{"rack": "2", corev1.LabelHostname: "worker-4"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-5", Labels: map[string]string{corev1.LabelHostname: "worker-5"}}},
+		},
+	}
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			Workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Canary: &lifecyclev1alpha1.CanaryBatch{Count: 1},
+			},
+			NodeGroups: []lifecyclev1alpha1.NodeGroup{
+				{
+					Name:         "rack-1",
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "1"}},
+				},
+				{
+					Name:         "rack-2",
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "2"}},
+				},
+				{
+					Name:         "rack-3",
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "3"}},
+				},
+			},
+		},
+	}
+
+	batches, err := workerBatches(upgradePlan, nodeList, nil)
+	require.NoError(t, err)
+
+	expectedBatches := []upgrade.WorkerBatch{
+		{Name: upgrade.CanaryBatch, Hostnames: []string{"worker-1"}, Concurrency: 1},
+		{Name: "rack-1", Hostnames: []string{"worker-2"}, Concurrency: 1},
+		{Name: "rack-2", Hostnames: []string{"worker-3", "worker-4"}, Concurrency: 1},
+		{ExcludedHostnames: []string{"worker-1", "worker-2", "worker-3", "worker-4"}, Concurrency: 1},
+	}
+	assert.Equal(t, expectedBatches, batches)
+}
+
+func TestSetNodeGroupCondition(t *testing.T) {
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{}
+	batch := upgrade.WorkerBatch{Name: "rack-1", Hostnames: []string{"worker-1"}}
+	conditionType := lifecyclev1alpha1.OperatingSystemUpgradedCondition
+
+	setNodeGroupCondition(upgradePlan, batch, conditionType, metav1.ConditionUnknown, lifecyclev1alpha1.UpgradePending, "pending", false)
+	setNodeGroupCondition(upgradePlan, batch, conditionType, metav1.ConditionFalse, lifecyclev1alpha1.UpgradeInProgress, "in progress", true)
+	setNodeGroupCondition(upgradePlan, batch, conditionType, metav1.ConditionUnknown, lifecyclev1alpha1.UpgradePending, "pending", false)
+
+	require.Len(t, upgradePlan.Status.NodeGroups, 1)
+
+	group := upgradePlan.Status.NodeGroups[0]
+	assert.Equal(t, "rack-1", group.Name)
+	assert.Equal(t, []string{"worker-1"}, group.Nodes)
+
+	require.Len(t, group.Conditions, 1)
+	assert.Equal(t, lifecyclev1alpha1.UpgradeInProgress, group.Conditions[0].Reason)
+	assert.Equal(t, "in progress", group.Conditions[0].Message)
+}
+
+func TestWorkerBatches_MissingHostname(t *testing.T) {
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+		},
+	}
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			Workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Canary: &lifecyclev1alpha1.CanaryBatch{Count: 1},
+			},
+		},
+	}
+
+	_, err := workerBatches(upgradePlan, nodeList, nil)
+	assert.EqualError(t, err, "worker node worker-1 is missing the kubernetes.io/hostname label")
+}
+
+func TestWorkerBatches_JoiningNodes(t *testing.T) {
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"rack": "1", corev1.LabelHostname: "worker-1"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Labels: map[string]string{"rack": "1", corev1.LabelHostname: "worker-0"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{corev1.LabelHostname: "worker-2"}}},
+		},
+	}
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			Workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Canary: &lifecyclev1alpha1.CanaryBatch{Count: 1},
+			},
+			NodeGroups: []lifecyclev1alpha1.NodeGroup{
+				{
+					Name:         "rack-1",
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "1"}},
+				},
+			},
+		},
+	}
+
+	// The canary batch was created while worker-0 was not yet part of the cluster.
+	batches, err := workerBatches(upgradePlan, nodeList, []string{"worker-1"})
+	require.NoError(t, err)
+
+	expectedBatches := []upgrade.WorkerBatch{
+		{Name: upgrade.CanaryBatch, Hostnames: []string{"worker-1"}, Concurrency: 1},
+		{Name: "rack-1", Hostnames: []string{"worker-0"}, Concurrency: 1},
+		{ExcludedHostnames: []string{"worker-0", "worker-1"}, Concurrency: 1},
+	}
+	assert.Equal(t, expectedBatches, batches)
+
+	// All workers are assigned to a group, the remaining batch is only added once an unmatched node joins.
+	nodeList.Items = nodeList.Items[:2]
+
+	batches, err = workerBatches(upgradePlan, nodeList, []string{"worker-1"})
+	require.NoError(t, err)
+	assert.Equal(t, expectedBatches[:2], batches)
+}
+
+func TestReconcileWorkerBatches_JoiningNode(t *testing.T) {
+	ctx := context.Background()
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			NodeGroups: []lifecyclev1alpha1.NodeGroup{
+				{
+					Name:         "rack-1",
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "1"}},
+				},
+			},
+		},
+	}
+
+	workerPlan := func(batch upgrade.WorkerBatch) *upgradecattlev1.Plan {
+		return upgrade.KubernetesWorkerPlan("abcdef", "v1.30.3+rke2r1", batch, nil, map[string]string{})
+	}
+
+	existing := workerPlan(upgrade.WorkerBatch{Name: "rack-1", Hostnames: []string{"worker-1"}, Concurrency: 1})
+	r := newFakeReconciler(existing)
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"rack": "1", corev1.LabelHostname: "worker-1"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{"rack": "1", corev1.LabelHostname: "worker-2"}}},
+		},
+	}
+
+	isUpgraded := func(nodes []corev1.Node) bool {
+		return !slices.ContainsFunc(nodes, func(node corev1.Node) bool {
+			return node.Name == "worker-2"
+		})
+	}
+
+	result, done, err := r.reconcileWorkerBatches(ctx, upgradePlan, lifecyclev1alpha1.KubernetesUpgradedCondition, nodeList, workerPlan, isUpgraded)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 1*time.Minute, result.RequeueAfter)
+
+	plan := &upgradecattlev1.Plan{}
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(existing), plan))
+	assert.Equal(t, []string{"worker-1", "worker-2"}, upgrade.BatchHostnames(plan.Spec.NodeSelector))
+
+	require.Len(t, upgradePlan.Status.NodeGroups, 1)
+	assert.Equal(t, []string{"worker-1", "worker-2"}, upgradePlan.Status.NodeGroups[0].Nodes)
+}
+
+func TestReconcileWorkerBatches_CanarySoak(t *testing.T) {
+	ctx := context.Background()
+
+	upgradePlan := &lifecyclev1alpha1.UpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "default"},
+		Spec: lifecyclev1alpha1.UpgradePlanSpec{
+			Workers: &lifecyclev1alpha1.WorkerUpgrade{
+				Canary: &lifecyclev1alpha1.CanaryBatch{Count: 1, SoakDuration: metav1.Duration{Duration: time.Hour}},
+			},
+		},
+	}
+
+	workerPlan := func(batch upgrade.WorkerBatch) *upgradecattlev1.Plan {
+		return upgrade.KubernetesWorkerPlan("abcdef", "v1.30.3+rke2r1", batch, nil, map[string]string{})
+	}
+
+	canaryPlan := workerPlan(upgrade.WorkerBatch{Name: upgrade.CanaryBatch, Hostnames: []string{"worker-1"}, Concurrency: 1})
+	r := newFakeReconciler(canaryPlan)
+
+	nodeList := &corev1.NodeList{
+		Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{corev1.LabelHostname: "worker-1"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{corev1.LabelHostname: "worker-2"}}},
+		},
+	}
+
+	isUpgraded := func(nodes []corev1.Node) bool {
+		return true
+	}
+
+	conditionType := lifecyclev1alpha1.KubernetesUpgradedCondition
+
+	result, done, err := r.reconcileWorkerBatches(ctx, upgradePlan, conditionType, nodeList, workerPlan, isUpgraded)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, time.Hour, result.RequeueAfter)
+
+	// The soak is excluded from the stage timeout.
+	require.Len(t, upgradePlan.Status.WaitPeriods, 1)
+	assert.Equal(t, lifecyclev1alpha1.CanarySoakReason, upgradePlan.Status.WaitPeriods[0].Reason)
+	assert.Nil(t, upgradePlan.Status.WaitPeriods[0].EndedAt)
+
+	plan := &upgradecattlev1.Plan{}
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(canaryPlan), plan))
+	plan.Annotations[upgrade.CanarySoakStartAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	require.NoError(t, r.Update(ctx, plan))
+
+	_, done, err = r.reconcileWorkerBatches(ctx, upgradePlan, conditionType, nodeList, workerPlan, isUpgraded)
+	require.NoError(t, err)
+	assert.False(t, done)
+
+	require.Len(t, upgradePlan.Status.WaitPeriods, 1)
+	assert.NotNil(t, upgradePlan.Status.WaitPeriods[0].EndedAt)
+}
@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+const dockerHubRegistry = "docker.io"
+
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Keychain holds the credentials used for each registry host.
+type Keychain map[string]Credentials
+
+// Resolve implements authn.Keychain. Registries without credentials are accessed anonymously.
+func (k Keychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	credentials, ok := k[registryHost(resource.RegistryStr())]
+	if !ok {
+		return authn.Anonymous, nil
+	}
+
+	return &authn.Basic{Username: credentials.Username, Password: credentials.Password}, nil
+}
+
+type dockerConfig struct {
+	Auths map[string]struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	} `json:"auths"`
+}
+
+// ParseDockerConfig parses the registry credentials of a "kubernetes.io/dockerconfigjson" Secret.
+func ParseDockerConfig(data []byte) (Keychain, error) {
+	config := dockerConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("decoding docker config: %w", err)
+	}
+
+	keychain := Keychain{}
+
+	for server, auth := range config.Auths {
+		credentials := Credentials{Username: auth.Username, Password: auth.Password}
+
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("decoding credentials for %s: %w", server, err)
+			}
+
+			username, password, found := strings.Cut(string(decoded), ":")
+			if !found {
+				return nil, fmt.Errorf("decoding credentials for %s: invalid format", server)
+			}
+
+			credentials = Credentials{Username: username, Password: password}
+		}
+
+		keychain[registryHost(server)] = credentials
+	}
+
+	return keychain, nil
+}
+
+// registryHost normalizes the server addresses used in docker configs, e.g. "https://index.docker.io/v1/".
+func registryHost(server string) string {
+	host := server
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case name.DefaultRegistry, "registry-1.docker.io":
+		return dockerHubRegistry
+	default:
+		return host
+	}
+}
@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Mirrors holds the mirror endpoints of each registry host, in order of preference.
+type Mirrors map[string][]string
+
+// ParseMirrors parses mirrors in the "<registry>=<mirror>[,<registry>=<mirror>...]" format.
+// Mirrors are accessed via HTTPS, falling back to plain HTTP if prefixed with "http://".
+func ParseMirrors(s string) (Mirrors, error) {
+	mirrors := Mirrors{}
+
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		registry, mirror, found := strings.Cut(entry, "=")
+		if !found || registry == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid mirror %q, expected <registry>=<mirror>", entry)
+		}
+
+		if _, err := parseMirror(mirror); err != nil {
+			return nil, fmt.Errorf("invalid mirror %q: %w", entry, err)
+		}
+
+		mirrors[registryHost(registry)] = append(mirrors[registryHost(registry)], mirror)
+	}
+
+	return mirrors, nil
+}
+
+func parseMirror(s string) (name.Registry, error) {
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return name.Registry{}, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return name.Registry{}, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	} else if u.Host == "" || strings.Trim(u.Path, "/") != "" {
+		return name.Registry{}, fmt.Errorf("expected a host")
+	}
+
+	var options []name.Option
+	if u.Scheme == "http" {
+		options = append(options, name.Insecure)
+	}
+
+	return name.NewRegistry(u.Host, options...)
+}
+
+// registries returns the mirrors of the registry followed by the registry itself.
+func (c *Client) registries(registry name.Registry) []name.Registry {
+	var registries []name.Registry
+
+	for _, mirror := range c.mirrors[registryHost(registry.RegistryStr())] {
+		// Mirrors have already been validated while parsing.
+		if r, err := parseMirror(mirror); err == nil {
+			registries = append(registries, r)
+		}
+	}
+
+	return append(registries, registry)
+}
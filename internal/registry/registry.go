@@ -0,0 +1,323 @@
+// Package registry retrieves files from container images and OCI artifacts
+// stored in registries implementing the OCI distribution specification.
+package registry
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// Annotation holding the file name of the layers of OCI artifacts.
+	titleAnnotation = "org.opencontainers.image.title"
+
+	mediaTypeCosignSimpleSigning types.MediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	cosignSignatureAnnotation                    = "dev.cosignproject.cosign/signature"
+
+	maxPayloadSize = 4 << 20
+	maxFileSize    = 16 << 20
+
+	requestTimeout = 5 * time.Minute
+)
+
+type Client struct {
+	transport http.RoundTripper
+	mirrors   Mirrors
+}
+
+// NewClient creates a client trusting the specified root CAs, or the system ones if nil.
+// Registries are accessed through their mirrors first, if any.
+func NewClient(rootCAs *x509.CertPool, mirrors Mirrors) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	return &Client{
+		transport: transport,
+		mirrors:   mirrors,
+	}
+}
+
+// PullFile returns the contents of the specified file from a container image or an OCI artifact.
+// Files of container images are looked up relative to the working directory of the image.
+func (c *Client) PullFile(ctx context.Context, reference, filename string, keychain Keychain) ([]byte, error) {
+	var data []byte
+
+	err := c.withRepository(ctx, reference, keychain, func(ref name.Reference, options []remote.Option) (err error) {
+		data, err = pullFile(ref, filename, options)
+		return err
+	})
+
+	return data, err
+}
+
+// Resolve returns the digest of the manifest referenced by the specified tag or digest.
+func (c *Client) Resolve(ctx context.Context, reference string, keychain Keychain) (string, error) {
+	var digest string
+
+	err := c.withRepository(ctx, reference, keychain, func(ref name.Reference, options []remote.Option) error {
+		desc, err := remote.Get(ref, options...)
+		if err != nil {
+			return err
+		}
+
+		digest = desc.Digest.String()
+		return nil
+	})
+
+	return digest, err
+}
+
+// CosignSignature is a signature of an image created by cosign.
+type CosignSignature struct {
+	// Payload is the signed simple signing payload which references the image digest.
+	Payload []byte
+	// Signature is the base64 encoded signature of the payload.
+	Signature string
+}
+
+// PullCosignSignatures returns the cosign signatures of the image with the specified digest.
+// The signatures are looked up under the "sha256-<hex>.sig" tag of the repository of the image.
+func (c *Client) PullCosignSignatures(ctx context.Context, reference, digest string, keychain Keychain) ([]CosignSignature, error) {
+	var signatures []CosignSignature
+
+	err := c.withRepository(ctx, reference, keychain, func(ref name.Reference, options []remote.Option) error {
+		img, err := remote.Image(ref.Context().Tag(strings.Replace(digest, ":", "-", 1)+".sig"), options...)
+		if err != nil {
+			return err
+		}
+
+		m, err := img.Manifest()
+		if err != nil {
+			return err
+		}
+
+		signatures = nil
+		for _, layer := range m.Layers {
+			signature, ok := layer.Annotations[cosignSignatureAnnotation]
+			if layer.MediaType != mediaTypeCosignSimpleSigning || !ok {
+				continue
+			}
+
+			payload, err := readLayer(img, layer, maxPayloadSize)
+			if err != nil {
+				return err
+			}
+
+			signatures = append(signatures, CosignSignature{Payload: payload, Signature: signature})
+		}
+
+		return nil
+	})
+
+	return signatures, err
+}
+
+// withRepository runs the specified function against the mirrors of the registry and then the registry itself,
+// until the function succeeds.
+func (c *Client) withRepository(ctx context.Context, reference string, keychain Keychain, f func(ref name.Reference, options []remote.Option) error) error {
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		return fmt.Errorf("parsing reference %q: %w", reference, err)
+	}
+
+	var errs []error
+
+	for _, registry := range c.registries(ref.Context().Registry) {
+		repository := registry.Repo(ref.Context().RepositoryStr())
+
+		var mirrored name.Reference = repository.Tag(ref.Identifier())
+		if _, ok := ref.(name.Digest); ok {
+			mirrored = repository.Digest(ref.Identifier())
+		}
+
+		if err = c.do(ctx, mirrored, keychain, f); err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("pulling %s from %s: %w", ref, registry, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Client) do(ctx context.Context, ref name.Reference, keychain Keychain, f func(ref name.Reference, options []remote.Option) error) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	return f(ref, []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithTransport(c.transport),
+		remote.WithAuthFromKeychain(keychain),
+	})
+}
+
+func pullFile(ref name.Reference, filename string, options []remote.Option) ([]byte, error) {
+	desc, err := remote.Get(ref, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var img v1.Image
+
+	if desc.MediaType.IsIndex() {
+		if img, err = selectImage(desc); err != nil {
+			return nil, err
+		}
+	} else if img, err = desc.Image(); err != nil {
+		return nil, err
+	}
+
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	// OCI artifacts store each file as a separate layer.
+	for _, layer := range m.Layers {
+		if layer.Annotations[titleAnnotation] == filename {
+			return readLayer(img, layer, maxFileSize)
+		}
+	}
+
+	if !slices.ContainsFunc(m.Layers, isFilesystemLayer) {
+		return nil, fmt.Errorf("file %s not found", filename)
+	}
+
+	// Container images store the file within their filesystem layers.
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("fetching image config: %w", err)
+	}
+
+	data, found, err := extractFile(img, cleanPath(path.Join("/", config.Config.WorkingDir, filename)))
+	if err != nil {
+		return nil, fmt.Errorf("extracting filesystem: %w", err)
+	} else if !found {
+		return nil, fmt.Errorf("file %s not found", filename)
+	}
+
+	return data, nil
+}
+
+// selectImage selects the image of an index matching the platform of the controller,
+// or the first one if there is no such image.
+func selectImage(desc *remote.Descriptor) (v1.Image, error) {
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := indexManifest.Manifests
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("image index does not contain any manifests")
+	}
+
+	selected := manifests[0]
+	for _, m := range manifests {
+		if m.Platform != nil && m.Platform.OS == "linux" && m.Platform.Architecture == runtime.GOARCH {
+			selected = m
+			break
+		}
+	}
+
+	return index.Image(selected.Digest)
+}
+
+func isFilesystemLayer(layer v1.Descriptor) bool {
+	return strings.Contains(string(layer.MediaType), "tar")
+}
+
+// readLayer returns the raw contents of a layer, verified against its digest.
+func readLayer(img v1.Image, desc v1.Descriptor, limit int64) ([]byte, error) {
+	if desc.Size > limit {
+		return nil, fmt.Errorf("layer %s exceeds the maximum size of %d bytes", desc.Digest, limit)
+	}
+
+	layer, err := img.LayerByDigest(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("fetching layer %s: %w", desc.Digest, err)
+	}
+	defer reader.Close()
+
+	data, err := readLimited(reader, limit)
+	if err != nil {
+		return nil, fmt.Errorf("reading layer %s: %w", desc.Digest, err)
+	}
+
+	return data, nil
+}
+
+// extractFile reads the specified file from the flattened filesystem of an image,
+// taking files deleted by later layers into account.
+func extractFile(img v1.Image, filePath string) ([]byte, bool, error) {
+	reader := mutate.Extract(img)
+	defer reader.Close()
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, false, nil
+		} else if err != nil {
+			return nil, false, err
+		}
+
+		if header.Typeflag != tar.TypeReg || cleanPath(header.Name) != filePath {
+			continue
+		}
+
+		data, err := readLimited(tarReader, maxFileSize)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return data, true, nil
+	}
+}
+
+func readLimited(reader io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("content exceeds the maximum size of %d bytes", limit)
+	}
+
+	return data, nil
+}
+
+func cleanPath(name string) string {
+	return path.Clean("/" + name)
+}
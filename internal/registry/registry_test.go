@@ -0,0 +1,312 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	"github.com/google/go-containerregistry/pkg/name"
+	ociregistry "github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry serves an in-memory registry, optionally requiring a bearer token
+// and serving tampered blobs.
+type fakeRegistry struct {
+	registry http.Handler
+	server   *httptest.Server
+	token    string
+	tampered map[string][]byte
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": f.token})
+		return
+	}
+
+	if f.token != "" && r.Header.Get("Authorization") != "Bearer "+f.token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="https://%s/token",service="registry"`, r.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if data, ok := f.tampered[r.URL.Path]; ok {
+		_, _ = w.Write(data)
+		return
+	}
+
+	f.registry.ServeHTTP(w, r)
+}
+
+func (f *fakeRegistry) host() string {
+	return strings.TrimPrefix(f.server.URL, "https://")
+}
+
+func (f *fakeRegistry) push(t *testing.T, tag string, artifact remote.Taggable) string {
+	ref, err := name.ParseReference(f.host() + "/edge/release-manifest:" + tag)
+	require.NoError(t, err)
+
+	options := []remote.Option{remote.WithTransport(f.server.Client().Transport)}
+
+	switch a := artifact.(type) {
+	case v1.ImageIndex:
+		require.NoError(t, remote.WriteIndex(ref, a, options...))
+	case v1.Image:
+		require.NoError(t, remote.Write(ref, a, options...))
+	}
+
+	return ref.String()
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *Client) {
+	fake := &fakeRegistry{registry: ociregistry.New(ociregistry.Logger(log.New(io.Discard, "", 0))), tampered: map[string][]byte{}}
+
+	fake.server = httptest.NewTLSServer(fake)
+	t.Cleanup(fake.server.Close)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(fake.server.Certificate())
+
+	return fake, NewClient(rootCAs, nil)
+}
+
+func filesystemLayer(t *testing.T, compressionAlgorithm compression.Compression, files map[string]string) v1.Layer {
+	buf := &bytes.Buffer{}
+	tarWriter := tar.NewWriter(buf)
+
+	for name, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tarWriter.Close())
+
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}, tarball.WithCompression(compressionAlgorithm))
+	require.NoError(t, err)
+
+	return layer
+}
+
+func artifact(t *testing.T, mediaType types.MediaType, data []byte, annotations map[string]string) v1.Image {
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(data, mediaType),
+		Annotations: annotations,
+	})
+	require.NoError(t, err)
+
+	return mutate.MediaType(img, types.OCIManifestSchema1)
+}
+
+func TestPullFile_Image(t *testing.T) {
+	fake, client := newFakeRegistry(t)
+
+	img, err := mutate.Config(empty.Image, v1.Config{WorkingDir: "/release"})
+	require.NoError(t, err)
+
+	img, err = mutate.AppendLayers(img,
+		filesystemLayer(t, compression.GZip, map[string]string{
+			"release/release_manifest.yaml": "old",
+			"release/removed.yaml":          "removed",
+			"etc/os-release":                "os",
+		}),
+		filesystemLayer(t, compression.ZStd, map[string]string{
+			"./release/release_manifest.yaml": "new",
+			"release/.wh.removed.yaml":        "",
+		}),
+	)
+	require.NoError(t, err)
+
+	other, err := mutate.Config(empty.Image, v1.Config{WorkingDir: "/other"})
+	require.NoError(t, err)
+
+	index := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: other, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "unknown"}}},
+		mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: runtime.GOARCH}}},
+	)
+
+	reference := fake.push(t, "3.1.0", index)
+
+	data, err := client.PullFile(t.Context(), reference, "release_manifest.yaml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	_, err = client.PullFile(t.Context(), reference, "removed.yaml", nil)
+	assert.ErrorContains(t, err, "file removed.yaml not found")
+
+	_, err = client.PullFile(t.Context(), reference, "missing.yaml", nil)
+	assert.ErrorContains(t, err, "file missing.yaml not found")
+
+	_, err = client.PullFile(t.Context(), fake.host()+"/edge/release-manifest:3.2.0", "release_manifest.yaml", nil)
+	assert.ErrorContains(t, err, "MANIFEST_UNKNOWN")
+}
+
+func TestPullFile_Artifact(t *testing.T) {
+	fake, client := newFakeRegistry(t)
+
+	reference := fake.push(t, "3.1.0", artifact(t, "application/yaml", []byte("releaseVersion: 3.1.0"),
+		map[string]string{titleAnnotation: "release_manifest.yaml"}))
+
+	fake.token = "secret-token"
+
+	_, err := client.PullFile(t.Context(), reference, "release_manifest.yaml", nil)
+	assert.ErrorContains(t, err, "401 Unauthorized")
+
+	keychain := Keychain{fake.host(): {Username: "user", Password: "pass"}}
+
+	data, err := client.PullFile(t.Context(), reference, "release_manifest.yaml", keychain)
+	require.NoError(t, err)
+	assert.Equal(t, "releaseVersion: 3.1.0", string(data))
+
+	_, err = client.PullFile(t.Context(), reference, "missing.yaml", keychain)
+	assert.ErrorContains(t, err, "file missing.yaml not found")
+}
+
+func TestPullFile_DigestMismatch(t *testing.T) {
+	fake, client := newFakeRegistry(t)
+
+	img := artifact(t, "application/yaml", []byte("releaseVersion: 3.1.0"), map[string]string{titleAnnotation: "release_manifest.yaml"})
+	reference := fake.push(t, "3.1.0", img)
+
+	layers, err := img.Layers()
+	require.NoError(t, err)
+
+	digest, err := layers[0].Digest()
+	require.NoError(t, err)
+
+	fake.tampered["/v2/edge/release-manifest/blobs/"+digest.String()] = []byte("releaseVersion: 3.2.0")
+
+	_, err = client.PullFile(t.Context(), reference, "release_manifest.yaml", nil)
+	assert.ErrorContains(t, err, "error verifying sha256 checksum")
+}
+
+func TestPullFile_Mirror(t *testing.T) {
+	fake, _ := newFakeRegistry(t)
+
+	fake.push(t, "3.1.0", artifact(t, "application/yaml", []byte("releaseVersion: 3.1.0"),
+		map[string]string{titleAnnotation: "release_manifest.yaml"}))
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(fake.server.Certificate())
+
+	client := NewClient(rootCAs, Mirrors{"registry.invalid": {fake.server.URL}})
+
+	data, err := client.PullFile(t.Context(), "registry.invalid/edge/release-manifest:3.1.0", "release_manifest.yaml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "releaseVersion: 3.1.0", string(data))
+}
+
+func TestPullCosignSignatures(t *testing.T) {
+	fake, client := newFakeRegistry(t)
+
+	img := artifact(t, "application/yaml", []byte("releaseVersion: 3.1.0"), map[string]string{titleAnnotation: "release_manifest.yaml"})
+	reference := fake.push(t, "3.1.0", img)
+
+	imageDigest, err := img.Digest()
+	require.NoError(t, err)
+	digest := imageDigest.String()
+
+	resolved, err := client.Resolve(t.Context(), reference, nil)
+	require.NoError(t, err)
+	assert.Equal(t, digest, resolved)
+
+	_, err = client.PullCosignSignatures(t.Context(), reference, digest, nil)
+	assert.ErrorContains(t, err, "MANIFEST_UNKNOWN")
+
+	payload := []byte(`{"critical":{"image":{"docker-manifest-digest":"` + digest + `"}}}`)
+	fake.push(t, strings.Replace(digest, ":", "-", 1)+".sig",
+		artifact(t, mediaTypeCosignSimpleSigning, payload, map[string]string{cosignSignatureAnnotation: "c2lnbmF0dXJl"}))
+
+	signatures, err := client.PullCosignSignatures(t.Context(), reference, digest, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []CosignSignature{{Payload: payload, Signature: "c2lnbmF0dXJl"}}, signatures)
+
+	pulled, err := client.PullFile(t.Context(), reference+"@"+digest, "release_manifest.yaml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "releaseVersion: 3.1.0", string(pulled))
+}
+
+func TestParseDockerConfig(t *testing.T) {
+	config := `{"auths": {
+		"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"},
+		"registry.suse.com": {"username": "suse", "password": "secret"}
+	}}`
+
+	keychain, err := ParseDockerConfig([]byte(config))
+	require.NoError(t, err)
+	assert.Equal(t, Keychain{
+		"docker.io":         {Username: "user", Password: "pass"},
+		"registry.suse.com": {Username: "suse", Password: "secret"},
+	}, keychain)
+
+	ref, err := name.ParseReference("alpine")
+	require.NoError(t, err)
+
+	authenticator, err := keychain.Resolve(ref.Context())
+	require.NoError(t, err)
+
+	authConfig, err := authenticator.Authorization()
+	require.NoError(t, err)
+	assert.Equal(t, "user", authConfig.Username)
+
+	_, err = ParseDockerConfig([]byte(`{"auths": {"registry.suse.com": {"auth": "dXNlcg=="}}}`))
+	assert.EqualError(t, err, "decoding credentials for registry.suse.com: invalid format")
+}
+
+func TestParseMirrors(t *testing.T) {
+	mirrors, err := ParseMirrors("docker.io=mirror.example.com:5000, docker.io=http://10.0.0.1:5000,registry.suse.com=mirror.local")
+	require.NoError(t, err)
+	assert.Equal(t, Mirrors{
+		"docker.io":         {"mirror.example.com:5000", "http://10.0.0.1:5000"},
+		"registry.suse.com": {"mirror.local"},
+	}, mirrors)
+
+	ref, err := name.ParseReference("alpine")
+	require.NoError(t, err)
+
+	client := NewClient(nil, mirrors)
+	registries := client.registries(ref.Context().Registry)
+
+	require.Len(t, registries, 3)
+	assert.Equal(t, "mirror.example.com:5000", registries[0].RegistryStr())
+	assert.Equal(t, "https", registries[0].Scheme())
+	assert.Equal(t, "10.0.0.1:5000", registries[1].RegistryStr())
+	assert.Equal(t, "http", registries[1].Scheme())
+	assert.Equal(t, name.DefaultRegistry, registries[2].RegistryStr())
+
+	mirrors, err = ParseMirrors("")
+	require.NoError(t, err)
+	assert.Empty(t, mirrors)
+
+	_, err = ParseMirrors("docker.io")
+	assert.EqualError(t, err, `invalid mirror "docker.io", expected <registry>=<mirror>`)
+
+	_, err = ParseMirrors("docker.io=ftp://mirror.local")
+	assert.EqualError(t, err, `invalid mirror "docker.io=ftp://mirror.local": unsupported scheme "ftp"`)
+}
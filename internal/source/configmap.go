@@ -0,0 +1,66 @@
+package source
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConfigMap reads release manifests from ConfigMaps in the namespace of the UpgradePlan.
+type ConfigMap struct {
+	Name string
+	Key  string
+
+	Reader client.Reader
+}
+
+func (s *ConfigMap) Fetch(ctx context.Context, namespace, releaseVersion string, verifier *Verifier) ([]byte, error) {
+	name := expand(s.Name, releaseVersion)
+
+	key := releaseManifestFile
+	if s.Key != "" {
+		key = expand(s.Key, releaseVersion)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := s.Reader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap); err != nil {
+		return nil, fmt.Errorf("retrieving config map %s/%s: %w", namespace, name, err)
+	}
+
+	data, err := configMapValue(configMap, key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyDetached(ctx, verifier, data, func() ([]byte, error) {
+		return configMapValue(configMap, key+signatureSuffix)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func configMapValue(configMap *corev1.ConfigMap, key string) ([]byte, error) {
+	if data, ok := configMap.Data[key]; ok {
+		return []byte(data), nil
+	}
+
+	if data, ok := configMap.BinaryData[key]; ok {
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("config map %s/%s does not contain %s", configMap.Namespace, configMap.Name, key)
+}
+
+func (s *ConfigMap) String() string {
+	if s.Key == "" {
+		return "configmap:" + s.Name
+	}
+
+	return fmt.Sprintf("configmap:%s/%s", s.Name, s.Key)
+}
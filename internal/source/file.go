@@ -0,0 +1,45 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// File reads release manifests from the local file system of the controller.
+// Only meant for testing purposes.
+type File struct {
+	Path string
+}
+
+func (s *File) Fetch(ctx context.Context, _, releaseVersion string, verifier *Verifier) ([]byte, error) {
+	path := expand(s.Path, releaseVersion)
+
+	data, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading release manifest: %w", err)
+	}
+
+	err = verifyDetached(ctx, verifier, data, func() ([]byte, error) {
+		return readFile(path + signatureSuffix)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readLimited(f)
+}
+
+func (s *File) String() string {
+	return "file://" + s.Path
+}
@@ -0,0 +1,191 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Git retrieves release manifests from Git repositories.
+// Repositories are cloned into memory, without requiring the git binary.
+type Git struct {
+	Repository        string
+	Revision          string
+	Path              string
+	CredentialsSecret string
+
+	Reader client.Reader
+}
+
+func (s *Git) Fetch(ctx context.Context, namespace, releaseVersion string, verifier *Verifier) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	auth, err := s.auth(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := s.clone(ctx, expand(s.Revision, releaseVersion), auth)
+	if err != nil {
+		return nil, fmt.Errorf("cloning %s: %w", s.Repository, err)
+	}
+
+	path := strings.TrimPrefix(expand(s.Path, releaseVersion), "/")
+
+	data, err := readCommitFile(commit, path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyDetached(ctx, verifier, data, func() ([]byte, error) {
+		return readCommitFile(commit, path+signatureSuffix)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// clone clones the specified branch, tag or commit of the repository into memory and returns its commit.
+// Only the requested branch or tag is cloned, while commits require the whole repository to be cloned.
+func (s *Git) clone(ctx context.Context, revision string, auth transport.AuthMethod) (*object.Commit, error) {
+	options := &git.CloneOptions{
+		URL:          s.Repository,
+		Auth:         auth,
+		SingleBranch: true,
+		Tags:         git.NoTags,
+	}
+
+	var hash plumbing.Hash
+
+	if revision != "" {
+		reference, err := s.findReference(ctx, revision, auth)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case reference != "":
+			options.ReferenceName = reference
+		case plumbing.IsHash(revision):
+			hash = plumbing.NewHash(revision)
+			options.SingleBranch = false
+		default:
+			return nil, fmt.Errorf("revision %s not found", revision)
+		}
+	}
+
+	repository, err := git.CloneContext(ctx, memory.NewStorage(), nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if hash.IsZero() {
+		head, err := repository.ResolveRevision(plumbing.Revision(plumbing.HEAD))
+		if err != nil {
+			return nil, fmt.Errorf("resolving HEAD: %w", err)
+		}
+
+		hash = *head
+	}
+
+	commit, err := repository.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving commit %s: %w", hash, err)
+	}
+
+	return commit, nil
+}
+
+// findReference returns the name of the branch or tag matching the revision, if any.
+func (s *Git) findReference(ctx context.Context, revision string, auth transport.AuthMethod) (plumbing.ReferenceName, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{s.Repository},
+	})
+
+	references, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("listing references: %w", err)
+	}
+
+	for _, name := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(revision),
+		plumbing.NewTagReferenceName(revision),
+	} {
+		if slices.ContainsFunc(references, func(reference *plumbing.Reference) bool { return reference.Name() == name }) {
+			return name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// readCommitFile reads the file from the tree of the commit.
+func readCommitFile(commit *object.Commit, path string) ([]byte, error) {
+	file, err := commit.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if file.Size > maxManifestSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", path, maxManifestSize)
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return data, nil
+}
+
+func (s *Git) String() string {
+	location := fmt.Sprintf("git+%s//%s", s.Repository, s.Path)
+	if s.Revision != "" {
+		location += "?ref=" + s.Revision
+	}
+
+	return location
+}
+
+// auth returns the basic authentication credentials of the credentials secret, if any.
+func (s *Git) auth(ctx context.Context, namespace string) (transport.AuthMethod, error) {
+	if s.CredentialsSecret == "" {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := s.Reader.Get(ctx, types.NamespacedName{Name: s.CredentialsSecret, Namespace: namespace}, secret); err != nil {
+		return nil, fmt.Errorf("retrieving credentials secret: %w", err)
+	}
+
+	username, password := secret.Data[corev1.BasicAuthUsernameKey], secret.Data[corev1.BasicAuthPasswordKey]
+	if len(username) == 0 || len(password) == 0 {
+		return nil, fmt.Errorf("credentials secret %s/%s must contain %s and %s",
+			namespace, secret.Name, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+	}
+
+	return &githttp.BasicAuth{Username: string(username), Password: string(password)}, nil
+}
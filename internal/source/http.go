@@ -0,0 +1,96 @@
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HTTP downloads release manifests from HTTP(S) servers,
+// optionally verifying them against a published SHA-256 checksum.
+type HTTP struct {
+	URL         string
+	ChecksumURL string
+
+	Client *http.Client
+}
+
+func (s *HTTP) Fetch(ctx context.Context, _, releaseVersion string, verifier *Verifier) ([]byte, error) {
+	manifestURL := expand(s.URL, releaseVersion)
+
+	data, err := s.download(ctx, manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", manifestURL, err)
+	}
+
+	err = verifyDetached(ctx, verifier, data, func() ([]byte, error) {
+		return s.download(ctx, manifestURL+signatureSuffix)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.ChecksumURL == "" {
+		return data, nil
+	}
+
+	checksumURL := expand(s.ChecksumURL, releaseVersion)
+
+	checksum, err := s.download(ctx, checksumURL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading checksum %s: %w", checksumURL, err)
+	}
+
+	// sha256sum format: "<checksum>  <file name>".
+	fields := strings.Fields(string(checksum))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("checksum %s is empty", checksumURL)
+	}
+
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %x", manifestURL, fields[0], sum)
+	}
+
+	return data, nil
+}
+
+func (s *HTTP) String() string {
+	return s.URL
+}
+
+func (s *HTTP) download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return readLimited(resp.Body)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("release manifest exceeds %d bytes", maxManifestSize)
+	}
+
+	return data, nil
+}
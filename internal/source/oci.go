@@ -0,0 +1,92 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/suse-edge/upgrade-controller/internal/registry"
+	"github.com/suse-edge/upgrade-controller/internal/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OCI pulls release manifests from container images or OCI artifacts tagged with the release version.
+type OCI struct {
+	Image      string
+	PullSecret string
+
+	Registry *registry.Client
+	Reader   client.Reader
+}
+
+func (s *OCI) Fetch(ctx context.Context, namespace, releaseVersion string, verifier *Verifier) ([]byte, error) {
+	image := upgrade.ContainerImage{
+		Name:    s.Image,
+		Version: strings.TrimPrefix(releaseVersion, "v"),
+	}
+
+	keychain, err := s.keychain(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := image.String()
+
+	if verifier != nil {
+		if reference, err = s.verify(ctx, reference, keychain, verifier); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := s.Registry.PullFile(ctx, reference, releaseManifestFile, keychain)
+	if err != nil {
+		return nil, fmt.Errorf("pulling %s: %w", reference, err)
+	}
+
+	return data, nil
+}
+
+func (s *OCI) String() string {
+	return "oci://" + s.Image
+}
+
+// verify verifies the cosign signatures of the image.
+// Returns the reference of the verified image, pinned to its digest.
+func (s *OCI) verify(ctx context.Context, reference string, keychain registry.Keychain, verifier *Verifier) (string, error) {
+	digest, err := s.Registry.Resolve(ctx, reference, keychain)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", reference, err)
+	}
+
+	signatures, err := s.Registry.PullCosignSignatures(ctx, reference, digest, keychain)
+	if err != nil {
+		return "", fmt.Errorf("pulling signatures of %s: %w", reference, err)
+	}
+
+	if err = verifier.VerifyImage(ctx, digest, signatures); err != nil {
+		return "", err
+	}
+
+	return reference + "@" + digest, nil
+}
+
+// keychain returns the credentials of the pull secret, if any.
+func (s *OCI) keychain(ctx context.Context, namespace string) (registry.Keychain, error) {
+	if s.PullSecret == "" {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := s.Reader.Get(ctx, types.NamespacedName{Name: s.PullSecret, Namespace: namespace}, secret); err != nil {
+		return nil, fmt.Errorf("retrieving pull secret: %w", err)
+	}
+
+	data, ok := secret.Data[corev1.DockerConfigJsonKey]
+	if !ok {
+		return nil, fmt.Errorf("pull secret %s/%s does not contain %s", namespace, secret.Name, corev1.DockerConfigJsonKey)
+	}
+
+	return registry.ParseDockerConfig(data)
+}
@@ -0,0 +1,141 @@
+// Package source retrieves release manifests from the locations they are published to.
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	"github.com/suse-edge/upgrade-controller/internal/registry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Name of the release manifest file within images and ConfigMaps.
+	releaseManifestFile = "release_manifest.yaml"
+
+	maxManifestSize = 16 << 20
+
+	requestTimeout = time.Minute
+)
+
+// Source retrieves the raw manifests of releases.
+type Source interface {
+	// Fetch returns the manifest of the specified release.
+	// Namespace is the namespace of the UpgradePlan requesting the release,
+	// which holds the Secrets and ConfigMaps referenced by the source.
+	// The signature of the manifest is verified if a verifier is specified.
+	Fetch(ctx context.Context, namespace, releaseVersion string, verifier *Verifier) ([]byte, error)
+	// String describes the location of the release manifests.
+	String() string
+}
+
+// Options contains the dependencies shared by the sources.
+type Options struct {
+	// Reader is used to look up Secrets and ConfigMaps.
+	Reader client.Reader
+	// Registry is used to pull release manifests from OCI registries.
+	Registry *registry.Client
+	// HTTPClient is used to download release manifests from HTTP(S) servers.
+	// Defaults to a client with a one minute timeout.
+	HTTPClient *http.Client
+}
+
+func (o *Options) httpClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// New creates the source specified in an UpgradePlan.
+func New(spec *lifecyclev1alpha1.ReleaseManifestSource, opts Options) (Source, error) {
+	switch {
+	case spec.OCI != nil:
+		return &OCI{Image: spec.OCI.Image, PullSecret: spec.OCI.PullSecret, Registry: opts.Registry, Reader: opts.Reader}, nil
+	case spec.HTTP != nil:
+		return &HTTP{URL: spec.HTTP.URL, ChecksumURL: spec.HTTP.ChecksumURL, Client: opts.httpClient()}, nil
+	case spec.ConfigMap != nil:
+		return &ConfigMap{Name: spec.ConfigMap.Name, Key: spec.ConfigMap.Key, Reader: opts.Reader}, nil
+	case spec.Git != nil:
+		return &Git{
+			Repository:        spec.Git.Repository,
+			Revision:          spec.Git.Revision,
+			Path:              spec.Git.Path,
+			CredentialsSecret: spec.Git.CredentialsSecret,
+			Reader:            opts.Reader,
+		}, nil
+	default:
+		return nil, fmt.Errorf("no release manifest source specified")
+	}
+}
+
+// Parse creates a source from its URL representation:
+//
+//   - oci://<registry>/<repository>
+//   - http(s)://<host>/<path>
+//   - configmap:<name>[/<key>]
+//   - git+http(s)://<host>/<repository>//<path>[?ref=<revision>]
+//   - file://<path>, for testing purposes only
+func Parse(location string, opts Options) (Source, error) {
+	scheme, rest, found := strings.Cut(location, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid release manifest source %q: missing scheme", location)
+	}
+
+	spec := &lifecyclev1alpha1.ReleaseManifestSource{}
+
+	switch scheme {
+	case "oci":
+		spec.OCI = &lifecyclev1alpha1.OCIReleaseManifestSource{Image: strings.TrimPrefix(rest, "//")}
+	case "http", "https":
+		spec.HTTP = &lifecyclev1alpha1.HTTPReleaseManifestSource{URL: location}
+	case "configmap":
+		name, key, _ := strings.Cut(rest, "/")
+		spec.ConfigMap = &lifecyclev1alpha1.ConfigMapReleaseManifestSource{Name: name, Key: key}
+	case "git+http", "git+https":
+		git, err := parseGitLocation(strings.TrimPrefix(location, "git+"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid release manifest source %q: %w", location, err)
+		}
+		spec.Git = git
+	case "file":
+		return &File{Path: strings.TrimPrefix(rest, "//")}, nil
+	default:
+		return nil, fmt.Errorf("invalid release manifest source %q: unsupported scheme %q", location, scheme)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid release manifest source %q: %w", location, err)
+	}
+
+	return New(spec, opts)
+}
+
+func parseGitLocation(location string) (*lifecyclev1alpha1.GitReleaseManifestSource, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, path, found := strings.Cut(u.Path, "//")
+	if !found {
+		return nil, fmt.Errorf("missing path within the repository, expected <repository>//<path>")
+	}
+
+	revision := u.Query().Get("ref")
+
+	u.Path, u.RawPath, u.RawQuery = repository, "", ""
+
+	return &lifecyclev1alpha1.GitReleaseManifestSource{Repository: u.String(), Revision: revision, Path: path}, nil
+}
+
+// expand replaces the release version placeholder in the location.
+func expand(location, releaseVersion string) string {
+	return strings.ReplaceAll(location, lifecyclev1alpha1.ReleaseManifestVersionPlaceholder, releaseVersion)
+}
@@ -0,0 +1,261 @@
+package source
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitclient "github.com/go-git/go-git/v5/plumbing/transport/client"
+	gitserver "github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const manifest = "releaseVersion: 3.1.0"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		location      string
+		expected      Source
+		expectedError string
+	}{
+		{
+			location: "oci://registry.suse.com/edge/release-manifest",
+			expected: &OCI{Image: "registry.suse.com/edge/release-manifest"},
+		},
+		{
+			location: "https://example.com/releases/{releaseVersion}/release_manifest.yaml",
+			expected: &HTTP{URL: "https://example.com/releases/{releaseVersion}/release_manifest.yaml", Client: &http.Client{Timeout: requestTimeout}},
+		},
+		{
+			location: "configmap:release-manifests/{releaseVersion}.yaml",
+			expected: &ConfigMap{Name: "release-manifests", Key: "{releaseVersion}.yaml"},
+		},
+		{
+			location: "configmap:release-manifest-{releaseVersion}",
+			expected: &ConfigMap{Name: "release-manifest-{releaseVersion}"},
+		},
+		{
+			location: "git+https://github.com/suse-edge/manifests.git//releases/{releaseVersion}.yaml?ref=main",
+			expected: &Git{Repository: "https://github.com/suse-edge/manifests.git", Revision: "main", Path: "releases/{releaseVersion}.yaml"},
+		},
+		{
+			location: "file:///etc/release-manifests/{releaseVersion}.yaml",
+			expected: &File{Path: "/etc/release-manifests/{releaseVersion}.yaml"},
+		},
+		{
+			location:      "git+https://github.com/suse-edge/manifests.git",
+			expectedError: "missing path within the repository",
+		},
+		{
+			location:      "configmap:",
+			expectedError: "release manifest config map name is required",
+		},
+		{
+			location:      "s3://bucket/release_manifest.yaml",
+			expectedError: `unsupported scheme "s3"`,
+		},
+		{
+			location:      "release_manifest.yaml",
+			expectedError: "missing scheme",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.location, func(t *testing.T) {
+			source, err := Parse(test.location, Options{})
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, source)
+			assert.Equal(t, test.location, source.String())
+		})
+	}
+}
+
+func TestHTTP_Fetch(t *testing.T) {
+	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte(manifest)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/3.1.0/release_manifest.yaml":
+			_, _ = w.Write([]byte(manifest))
+		case "/3.1.0/release_manifest.yaml.sha256":
+			_, _ = fmt.Fprintf(w, "%s  release_manifest.yaml\n", checksum)
+		case "/3.1.0/invalid.sha256":
+			_, _ = fmt.Fprintf(w, "%x  release_manifest.yaml\n", sha256.Sum256(nil))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := &HTTP{
+		URL:         server.URL + "/{releaseVersion}/release_manifest.yaml",
+		ChecksumURL: server.URL + "/{releaseVersion}/release_manifest.yaml.sha256",
+		Client:      server.Client(),
+	}
+
+	data, err := source.Fetch(t.Context(), "default", "3.1.0", nil)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, string(data))
+
+	_, err = source.Fetch(t.Context(), "default", "3.2.0", nil)
+	assert.EqualError(t, err, fmt.Sprintf("downloading %s/3.2.0/release_manifest.yaml: unexpected status code 404", server.URL))
+
+	source.ChecksumURL = server.URL + "/{releaseVersion}/invalid.sha256"
+
+	_, err = source.Fetch(t.Context(), "default", "3.1.0", nil)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestConfigMap_Fetch(t *testing.T) {
+	reader := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-manifests", Namespace: "upgrade-controller-system"},
+		Data:       map[string]string{"3.1.0.yaml": manifest},
+	}).Build()
+
+	source := &ConfigMap{Name: "release-manifests", Key: "{releaseVersion}.yaml", Reader: reader}
+
+	data, err := source.Fetch(t.Context(), "upgrade-controller-system", "3.1.0", nil)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, string(data))
+
+	_, err = source.Fetch(t.Context(), "upgrade-controller-system", "3.2.0", nil)
+	assert.EqualError(t, err, "config map upgrade-controller-system/release-manifests does not contain 3.2.0.yaml")
+
+	_, err = source.Fetch(t.Context(), "default", "3.1.0", nil)
+	assert.ErrorContains(t, err, "retrieving config map default/release-manifests")
+}
+
+func TestFile_Fetch(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "3.1.0.yaml"), []byte(manifest), 0o600))
+
+	source := &File{Path: filepath.Join(dir, "{releaseVersion}.yaml")}
+
+	data, err := source.Fetch(t.Context(), "default", "3.1.0", nil)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, string(data))
+
+	_, err = source.Fetch(t.Context(), "default", "3.2.0", nil)
+	assert.ErrorContains(t, err, "reading release manifest")
+}
+
+func TestGit_Fetch(t *testing.T) {
+	fs := memfs.New()
+
+	repository, err := git.InitWithOptions(memory.NewStorage(), fs, git.InitOptions{
+		DefaultBranch: plumbing.NewBranchReferenceName("main"),
+	})
+	require.NoError(t, err)
+
+	worktree, err := repository.Worktree()
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+
+	commit := func(path, content string) plumbing.Hash {
+		require.NoError(t, util.WriteFile(fs, path, []byte(content), 0o644))
+
+		_, err := worktree.Add(path)
+		require.NoError(t, err)
+
+		hash, err := worktree.Commit("Add "+path, &git.CommitOptions{Author: signature})
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	initial := commit("releases/3.1.0.yaml", manifest)
+	_, err = repository.CreateTag("3.1.0", initial, &git.CreateTagOptions{Tagger: signature, Message: "Release 3.1.0"})
+	require.NoError(t, err)
+
+	commit("releases/3.2.0.yaml", "releaseVersion: 3.2.0")
+
+	endpoint, err := transport.NewEndpoint("memory://releases")
+	require.NoError(t, err)
+
+	gitclient.InstallProtocol("memory", gitserver.NewServer(gitserver.MapLoader{endpoint.String(): repository.Storer}))
+	t.Cleanup(func() { gitclient.InstallProtocol("memory", nil) })
+
+	source := &Git{Repository: "memory://releases", Path: "/releases/{releaseVersion}.yaml"}
+
+	data, err := source.Fetch(t.Context(), "default", "3.2.0", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "releaseVersion: 3.2.0", string(data))
+
+	_, err = source.Fetch(t.Context(), "default", "3.3.0", nil)
+	assert.ErrorContains(t, err, "reading releases/3.3.0.yaml")
+
+	source.Revision = "main"
+
+	data, err = source.Fetch(t.Context(), "default", "3.1.0", nil)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, string(data))
+
+	source.Revision = "{releaseVersion}"
+
+	data, err = source.Fetch(t.Context(), "default", "3.1.0", nil)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, string(data))
+
+	_, err = source.Fetch(t.Context(), "default", "3.2.0", nil)
+	assert.EqualError(t, err, "cloning memory://releases: revision 3.2.0 not found")
+
+	source.Revision = initial.String()
+
+	data, err = source.Fetch(t.Context(), "default", "3.1.0", nil)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, string(data))
+
+	_, err = source.Fetch(t.Context(), "default", "3.2.0", nil)
+	assert.ErrorContains(t, err, "reading releases/3.2.0.yaml")
+}
+
+func TestGit_FetchCredentials(t *testing.T) {
+	var username, password string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	reader := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "git-credentials", Namespace: "default"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("user"),
+			corev1.BasicAuthPasswordKey: []byte("pass"),
+		},
+	}).Build()
+
+	source := &Git{Repository: server.URL + "/releases.git", Path: "release_manifest.yaml", CredentialsSecret: "git-credentials", Reader: reader}
+
+	_, err := source.Fetch(t.Context(), "default", "3.1.0", nil)
+	assert.ErrorContains(t, err, "cloning "+server.URL+"/releases.git")
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "pass", password)
+
+	_, err = source.Fetch(t.Context(), "other", "3.1.0", nil)
+	assert.ErrorContains(t, err, "retrieving credentials secret")
+}
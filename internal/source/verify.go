@@ -0,0 +1,197 @@
+package source
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/suse-edge/upgrade-controller/internal/registry"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Suffix of the detached signatures of release manifests retrieved from sources other than OCI registries.
+const signatureSuffix = ".sig"
+
+// ErrVerificationFailed indicates that a release manifest is not signed by any of the trusted keys.
+var ErrVerificationFailed = errors.New("release manifest verification failed")
+
+// Verifier verifies the signatures of release manifests against trusted public keys.
+// Signatures are expected in the format produced by cosign: images are verified against
+// their cosign signatures, while other sources provide detached signatures created via "cosign sign-blob".
+type Verifier struct {
+	// Keys are the trusted public keys.
+	Keys []crypto.PublicKey
+	// KeysSecret references a Secret holding additional PEM encoded public keys.
+	// The Secret is read on every verification, allowing keys to be rotated without restarting the controller.
+	KeysSecret *types.NamespacedName
+
+	Reader client.Reader
+}
+
+// ParsePublicKeys decodes PEM encoded PKIX public keys.
+func ParsePublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public keys found")
+	}
+
+	return keys, nil
+}
+
+// VerifyBlob verifies a detached base64 encoded signature of the data.
+func (v *Verifier) VerifyBlob(ctx context.Context, data, signature []byte) error {
+	keys, err := v.keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = verifySignature(keys, data, string(signature)); err != nil {
+		return fmt.Errorf("%w: %w", ErrVerificationFailed, err)
+	}
+
+	return nil
+}
+
+// VerifyImage verifies that at least one of the cosign signatures is valid and signs the image with the specified digest.
+func (v *Verifier) VerifyImage(ctx context.Context, digest string, signatures []registry.CosignSignature) error {
+	keys, err := v.keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(signatures) == 0 {
+		return fmt.Errorf("%w: image %s is not signed", ErrVerificationFailed, digest)
+	}
+
+	var errs []error
+
+	for _, signature := range signatures {
+		if err = verifySignature(keys, signature.Payload, signature.Signature); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		payload := struct {
+			Critical struct {
+				Image struct {
+					DockerManifestDigest string `json:"docker-manifest-digest"`
+				} `json:"image"`
+			} `json:"critical"`
+		}{}
+		if err = json.Unmarshal(signature.Payload, &payload); err != nil {
+			errs = append(errs, fmt.Errorf("decoding signature payload: %w", err))
+			continue
+		}
+
+		if signed := payload.Critical.Image.DockerManifestDigest; signed != digest {
+			errs = append(errs, fmt.Errorf("signature is for image %s", signed))
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: image %s: %w", ErrVerificationFailed, digest, errors.Join(errs...))
+}
+
+func (v *Verifier) keys(ctx context.Context) ([]crypto.PublicKey, error) {
+	keys := slices.Clone(v.Keys)
+
+	if v.KeysSecret != nil {
+		secret := &corev1.Secret{}
+		if err := v.Reader.Get(ctx, *v.KeysSecret, secret); err != nil {
+			return nil, fmt.Errorf("retrieving verification keys secret: %w", err)
+		}
+
+		for name, data := range secret.Data {
+			secretKeys, err := ParsePublicKeys(data)
+			if err != nil {
+				return nil, fmt.Errorf("verification keys secret %s, key %s: %w", v.KeysSecret, name, err)
+			}
+
+			keys = append(keys, secretKeys...)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no verification keys configured")
+	}
+
+	return keys, nil
+}
+
+// verifySignature verifies the base64 encoded signature of the data against any of the keys.
+func verifySignature(keys []crypto.PublicKey, data []byte, encodedSignature string) error {
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedSignature))
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+
+	for _, key := range keys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest[:], signature) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil ||
+				rsa.VerifyPSS(k, crypto.SHA256, digest[:], signature, nil) == nil {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, data, signature) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("signature does not match any of the trusted keys")
+}
+
+// verifyDetached verifies the release manifest against its detached signature, if a verifier is specified.
+func verifyDetached(ctx context.Context, verifier *Verifier, data []byte, fetchSignature func() ([]byte, error)) error {
+	if verifier == nil {
+		return nil
+	}
+
+	signature, err := fetchSignature()
+	if err != nil {
+		return fmt.Errorf("retrieving signature: %w", err)
+	}
+
+	return verifier.VerifyBlob(ctx, data, signature)
+}
@@ -0,0 +1,131 @@
+package source
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/upgrade-controller/internal/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func generateKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	digest := sha256.Sum256(data)
+
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	return []byte(base64.StdEncoding.EncodeToString(signature))
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	_, first := generateKey(t)
+	_, second := generateKey(t)
+
+	keys, err := ParsePublicKeys(append(first, second...))
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	_, err = ParsePublicKeys([]byte("not a key"))
+	assert.EqualError(t, err, "no public keys found")
+}
+
+func TestVerifier_VerifyBlob(t *testing.T) {
+	key, publicKey := generateKey(t)
+	otherKey, _ := generateKey(t)
+
+	keys, err := ParsePublicKeys(publicKey)
+	require.NoError(t, err)
+
+	verifier := &Verifier{Keys: keys}
+
+	assert.NoError(t, verifier.VerifyBlob(t.Context(), []byte(manifest), sign(t, key, []byte(manifest))))
+
+	err = verifier.VerifyBlob(t.Context(), []byte(manifest), sign(t, otherKey, []byte(manifest)))
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+	assert.ErrorContains(t, err, "signature does not match any of the trusted keys")
+
+	err = verifier.VerifyBlob(t.Context(), []byte("releaseVersion: 3.2.0"), sign(t, key, []byte(manifest)))
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+
+	_, err = (&Verifier{}).keys(t.Context())
+	assert.EqualError(t, err, "no verification keys configured")
+}
+
+func TestVerifier_VerifyImage(t *testing.T) {
+	key, publicKey := generateKey(t)
+
+	reader := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-manifest-keys", Namespace: "upgrade-controller-system"},
+		Data:       map[string][]byte{"cosign.pub": publicKey},
+	}).Build()
+
+	verifier := &Verifier{
+		KeysSecret: &types.NamespacedName{Name: "release-manifest-keys", Namespace: "upgrade-controller-system"},
+		Reader:     reader,
+	}
+
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("image")))
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"registry.suse.com/edge/release-manifest"},`+
+		`"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`, digest))
+
+	signatures := []registry.CosignSignature{{Payload: payload, Signature: string(sign(t, key, payload))}}
+
+	assert.NoError(t, verifier.VerifyImage(t.Context(), digest, signatures))
+
+	otherDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("other")))
+
+	err := verifier.VerifyImage(t.Context(), otherDigest, signatures)
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+	assert.ErrorContains(t, err, "signature is for image "+digest)
+
+	err = verifier.VerifyImage(t.Context(), digest, nil)
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+	assert.ErrorContains(t, err, "is not signed")
+}
+
+func TestFile_FetchVerified(t *testing.T) {
+	key, publicKey := generateKey(t)
+
+	keys, err := ParsePublicKeys(publicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "3.1.0.yaml"), []byte(manifest), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "3.1.0.yaml.sig"), sign(t, key, []byte(manifest)), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "3.2.0.yaml"), []byte("releaseVersion: 3.2.0"), 0o600))
+
+	source := &File{Path: filepath.Join(dir, "{releaseVersion}.yaml")}
+	verifier := &Verifier{Keys: keys}
+
+	data, err := source.Fetch(t.Context(), "default", "3.1.0", verifier)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, string(data))
+
+	_, err = source.Fetch(t.Context(), "default", "3.2.0", verifier)
+	assert.ErrorContains(t, err, "retrieving signature")
+	assert.NotErrorIs(t, err, ErrVerificationFailed)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -16,11 +18,36 @@ const (
 
 	ReleaseAnnotation = "lifecycle.suse.com/release"
 
+	// RollbackAnnotation holds the release version whose failed HelmChart upgrade has been rolled back.
+	RollbackAnnotation = "lifecycle.suse.com/rollback"
+
+	// PreviousChartSourceAnnotation holds the chart source of a HelmChart prior to its upgrade,
+	// which is restored if the upgrade is rolled back.
+	PreviousChartSourceAnnotation = "lifecycle.suse.com/previous-chart-source"
+
+	// RollbackRevisionAnnotation holds the latest Helm release revision at the time a HelmChart rollback started.
+	// The rollback is only evaluated once a newer revision has been produced.
+	RollbackRevisionAnnotation = "lifecycle.suse.com/rollback-revision"
+
+	// HookLabel holds the name of the hook which a Job has been created for.
+	HookLabel = "lifecycle.suse.com/hook"
+
+	// SuspendedConcurrencyAnnotation holds the original concurrency of a suspended SUC Plan.
+	SuspendedConcurrencyAnnotation = "lifecycle.suse.com/suspended-concurrency"
+
 	ControlPlaneLabel = "node-role.kubernetes.io/control-plane"
+	EtcdLabel         = "node-role.kubernetes.io/etcd"
+
+	// SUCPlanLabel and SUCNodeLabel are set by SUC on the jobs which upgrade the nodes.
+	SUCPlanLabel = "upgrade.cattle.io/plan"
+	SUCNodeLabel = "upgrade.cattle.io/node"
 
 	KubeSystemNamespace = "kube-system"
 	SUCNamespace        = "cattle-system"
 
+	// Image used by the SUC jobs which run commands on the host via chroot.
+	hostCommandImage = "registry.suse.com/bci/bci-base:15.6"
+
 	controlPlaneKey = "control-plane"
 	workersKey      = "workers"
 
@@ -43,7 +70,7 @@ func GenerateSuffix() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-func baseUpgradePlan(name string, drain bool, labels map[string]string) *upgradecattlev1.Plan {
+func baseUpgradePlan(name string, drain *upgradecattlev1.DrainSpec, labels map[string]string) *upgradecattlev1.Plan {
 	const (
 		kind               = "Plan"
 		apiVersion         = "upgrade.cattle.io/v1"
@@ -62,20 +89,82 @@ func baseUpgradePlan(name string, drain bool, labels map[string]string) *upgrade
 		},
 		Spec: upgradecattlev1.PlanSpec{
 			ServiceAccountName: serviceAccountName,
+			Drain:              drain,
 		},
 	}
 
-	if drain {
-		timeout := intstr.FromString("15m")
-		deleteEmptyDirData := true
-		ignoreDaemonSets := true
-		plan.Spec.Drain = &upgradecattlev1.DrainSpec{
-			Timeout:            &timeout,
-			DeleteEmptydirData: &deleteEmptyDirData,
-			IgnoreDaemonSets:   &ignoreDaemonSets,
-			Force:              true,
-		}
+	return plan
+}
+
+// controlPlaneTolerations returns the tolerations required for scheduling SUC jobs on control plane nodes.
+func controlPlaneTolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{
+			Key:      "CriticalAddonsOnly",
+			Operator: "Equal",
+			Value:    "true",
+			Effect:   "NoExecute",
+		},
+		{
+			Key:      ControlPlaneLabel,
+			Operator: "Equal",
+			Value:    "",
+			Effect:   "NoSchedule",
+		},
+		{
+			Key:      EtcdLabel,
+			Operator: "Equal",
+			Value:    "",
+			Effect:   "NoExecute",
+		},
 	}
+}
 
-	return plan
+// DrainSpec returns the SUC drain specification for the given options.
+// Options which are not specified fall back to the defaults.
+func DrainSpec(options *lifecyclev1alpha1.DrainOptions) *upgradecattlev1.DrainSpec {
+	timeout := intstr.FromString("15m")
+	deleteEmptyDirData := true
+	ignoreDaemonSets := true
+
+	drain := &upgradecattlev1.DrainSpec{
+		Timeout:            &timeout,
+		DeleteEmptydirData: &deleteEmptyDirData,
+		IgnoreDaemonSets:   &ignoreDaemonSets,
+		Force:              true,
+	}
+
+	if options == nil {
+		return drain
+	}
+
+	if options.Timeout != nil {
+		timeout = intstr.FromString(options.Timeout.Duration.String())
+	}
+
+	if options.GracePeriod != nil {
+		gracePeriod := int32(options.GracePeriod.Duration.Seconds())
+		drain.GracePeriod = &gracePeriod
+	}
+
+	if options.SkipWaitForDeleteTimeout != nil {
+		drain.SkipWaitForDeleteTimeout = int(options.SkipWaitForDeleteTimeout.Duration.Seconds())
+	}
+
+	if options.Force != nil {
+		drain.Force = *options.Force
+	}
+
+	if options.DeleteEmptydirData != nil {
+		deleteEmptyDirData = *options.DeleteEmptydirData
+	}
+
+	if options.IgnoreDaemonSets != nil {
+		ignoreDaemonSets = *options.IgnoreDaemonSets
+	}
+
+	drain.PodSelector = options.PodSelector
+	drain.DisableEviction = options.DisableEviction
+
+	return drain
 }
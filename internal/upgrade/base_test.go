@@ -2,9 +2,12 @@ package upgrade
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
@@ -28,7 +31,7 @@ func TestGenerateSuffix(t *testing.T) {
 }
 
 func TestBaseUpgradePlan_DrainEnabled(t *testing.T) {
-	upgradePlan := baseUpgradePlan("upgrade-plan-1", false, nil)
+	upgradePlan := baseUpgradePlan("upgrade-plan-1", nil, nil)
 
 	assert.Equal(t, "Plan", upgradePlan.TypeMeta.Kind)
 	assert.Equal(t, "upgrade.cattle.io/v1", upgradePlan.TypeMeta.APIVersion)
@@ -42,7 +45,7 @@ func TestBaseUpgradePlan_DrainEnabled(t *testing.T) {
 }
 
 func TestBaseUpgradePlan_DrainDisabled(t *testing.T) {
-	upgradePlan := baseUpgradePlan("upgrade-plan-1", true, nil)
+	upgradePlan := baseUpgradePlan("upgrade-plan-1", DrainSpec(nil), nil)
 
 	assert.Equal(t, "Plan", upgradePlan.TypeMeta.Kind)
 	assert.Equal(t, "upgrade.cattle.io/v1", upgradePlan.TypeMeta.APIVersion)
@@ -58,3 +61,29 @@ func TestBaseUpgradePlan_DrainDisabled(t *testing.T) {
 	assert.Equal(t, ptr.To(true), upgradePlan.Spec.Drain.IgnoreDaemonSets)
 	assert.Equal(t, ptr.To(intstr.FromString("15m")), upgradePlan.Spec.Drain.Timeout)
 }
+
+func TestDrainSpec_CustomOptions(t *testing.T) {
+	podSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "stateless"}}
+
+	options := &lifecyclev1alpha1.DrainOptions{
+		Timeout:                  &metav1.Duration{Duration: time.Hour},
+		GracePeriod:              &metav1.Duration{Duration: 10 * time.Minute},
+		PodSelector:              podSelector,
+		SkipWaitForDeleteTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+		Force:                    ptr.To(false),
+		DisableEviction:          true,
+		DeleteEmptydirData:       ptr.To(false),
+	}
+
+	drain := DrainSpec(options)
+	require.NotNil(t, drain)
+
+	assert.Equal(t, ptr.To(intstr.FromString("1h0m0s")), drain.Timeout)
+	assert.Equal(t, ptr.To(int32(600)), drain.GracePeriod)
+	assert.Equal(t, podSelector, drain.PodSelector)
+	assert.Equal(t, 300, drain.SkipWaitForDeleteTimeout)
+	assert.False(t, drain.Force)
+	assert.True(t, drain.DisableEviction)
+	assert.Equal(t, ptr.To(false), drain.DeleteEmptydirData)
+	assert.Equal(t, ptr.To(true), drain.IgnoreDaemonSets)
+}
@@ -0,0 +1,51 @@
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+
+	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EtcdSnapshotName returns the name of the etcd snapshot taken before upgrading to the specified release.
+func EtcdSnapshotName(releaseVersion, nameSuffix string) string {
+	return fmt.Sprintf("pre-upgrade-%s-%s", strings.ReplaceAll(releaseVersion, ".", "-"), nameSuffix)
+}
+
+// EtcdSnapshotPlan returns the SUC Plan which takes an on-demand etcd snapshot on the specified node.
+func EtcdSnapshotPlan(nameSuffix, kubernetesVersion, snapshotName, hostname string, labels map[string]string) *upgradecattlev1.Plan {
+	binary := "rke2"
+	if strings.Contains(kubernetesVersion, "k3s") {
+		binary = "k3s"
+	}
+
+	labels["etcd-snapshot"] = "control-plane"
+	plan := baseUpgradePlan(fmt.Sprintf("etcd-snapshot-%s", nameSuffix), nil, labels)
+	plan.Spec.Concurrency = 1
+	plan.Spec.NodeSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      corev1.LabelHostname,
+				Operator: "In",
+				Values: []string{
+					hostname,
+				},
+			},
+		},
+	}
+	plan.Spec.Tolerations = controlPlaneTolerations()
+	// SUC requires either a version or a channel, the snapshot name ensures that the plan is applied exactly once.
+	plan.Spec.Version = snapshotName
+	plan.Spec.Upgrade = &upgradecattlev1.ContainerSpec{
+		Image:   hostCommandImage,
+		Command: []string{"chroot", "/host"},
+		Args: []string{
+			"sh", "-c",
+			fmt.Sprintf("PATH=$PATH:/opt/%[1]s/bin:/usr/local/bin %[1]s etcd-snapshot save --name %[2]s", binary, snapshotName),
+		},
+	}
+
+	return plan
+}
@@ -0,0 +1,60 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtcdSnapshotName(t *testing.T) {
+	assert.Equal(t, "pre-upgrade-3-1-0-abcdef", EtcdSnapshotName("3.1.0", planNameSuffix))
+}
+
+func TestEtcdSnapshotPlan_RKE2(t *testing.T) {
+	addLabels := map[string]string{
+		"lifecycle.suse.com/x": "z",
+	}
+
+	expectedLabels := map[string]string{
+		"lifecycle.suse.com/x": "z",
+		"etcd-snapshot":        "control-plane",
+	}
+
+	upgradePlan := EtcdSnapshotPlan(planNameSuffix, "v1.30.2+rke2r1", "pre-upgrade-3-1-0-abcdef", "cp-1", addLabels)
+	require.NotNil(t, upgradePlan)
+
+	assert.Equal(t, "etcd-snapshot-abcdef", upgradePlan.ObjectMeta.Name)
+	assert.Equal(t, "cattle-system", upgradePlan.ObjectMeta.Namespace)
+	assert.Equal(t, expectedLabels, upgradePlan.ObjectMeta.Labels)
+
+	require.Len(t, upgradePlan.Spec.NodeSelector.MatchExpressions, 1)
+
+	matchExpression := upgradePlan.Spec.NodeSelector.MatchExpressions[0]
+	assert.Equal(t, "kubernetes.io/hostname", matchExpression.Key)
+	assert.EqualValues(t, "In", matchExpression.Operator)
+	assert.Equal(t, []string{"cp-1"}, matchExpression.Values)
+
+	require.NotNil(t, upgradePlan.Spec.Upgrade)
+	assert.Equal(t, "registry.suse.com/bci/bci-base:15.6", upgradePlan.Spec.Upgrade.Image)
+	assert.Equal(t, []string{"chroot", "/host"}, upgradePlan.Spec.Upgrade.Command)
+	assert.Equal(t, []string{
+		"sh", "-c", "PATH=$PATH:/opt/rke2/bin:/usr/local/bin rke2 etcd-snapshot save --name pre-upgrade-3-1-0-abcdef",
+	}, upgradePlan.Spec.Upgrade.Args)
+
+	assert.Equal(t, "pre-upgrade-3-1-0-abcdef", upgradePlan.Spec.Version)
+	assert.EqualValues(t, 1, upgradePlan.Spec.Concurrency)
+	assert.False(t, upgradePlan.Spec.Cordon)
+	assert.Nil(t, upgradePlan.Spec.Drain)
+	assert.Len(t, upgradePlan.Spec.Tolerations, 3)
+}
+
+func TestEtcdSnapshotPlan_K3s(t *testing.T) {
+	upgradePlan := EtcdSnapshotPlan(planNameSuffix, "v1.30.2+k3s1", "pre-upgrade-3-1-0-abcdef", "cp-1", map[string]string{})
+	require.NotNil(t, upgradePlan)
+
+	require.NotNil(t, upgradePlan.Spec.Upgrade)
+	assert.Equal(t, []string{
+		"sh", "-c", "PATH=$PATH:/opt/k3s/bin:/usr/local/bin k3s etcd-snapshot save --name pre-upgrade-3-1-0-abcdef",
+	}, upgradePlan.Spec.Upgrade.Args)
+}
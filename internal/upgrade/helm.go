@@ -22,6 +22,10 @@ const (
 	ChartStateInProgress
 	ChartStateFailed
 	ChartStateSucceeded
+	ChartStateRollbackInProgress
+	ChartStateRolledBack
+	ChartStateRollbackFailed
+	ChartStateRollbackUnavailable
 )
 
 func (s HelmChartState) FormattedMessage(chart string) string {
@@ -38,6 +42,14 @@ func (s HelmChartState) FormattedMessage(chart string) string {
 		return fmt.Sprintf("Chart %s upgrade failed", chart)
 	case ChartStateSucceeded:
 		return fmt.Sprintf("Chart %s upgrade succeeded", chart)
+	case ChartStateRollbackInProgress:
+		return fmt.Sprintf("Chart %s upgrade failed, rollback to the previous release is in progress", chart)
+	case ChartStateRolledBack:
+		return fmt.Sprintf("Chart %s upgrade failed, rolled back to the previous release", chart)
+	case ChartStateRollbackFailed:
+		return fmt.Sprintf("Chart %s upgrade failed, rollback to the previous release failed", chart)
+	case ChartStateRollbackUnavailable:
+		return fmt.Sprintf("Chart %s upgrade failed, rollback is not possible as the source of the previous release is unknown", chart)
 	default:
 		return ""
 	}
@@ -27,6 +27,18 @@ func TestHelmChartState_FormattedMessage(t *testing.T) {
 	state = ChartStateSucceeded
 	assert.Equal(t, "Chart metal3 upgrade succeeded", state.FormattedMessage(chart))
 
+	state = ChartStateRollbackInProgress
+	assert.Equal(t, "Chart metal3 upgrade failed, rollback to the previous release is in progress", state.FormattedMessage(chart))
+
+	state = ChartStateRolledBack
+	assert.Equal(t, "Chart metal3 upgrade failed, rolled back to the previous release", state.FormattedMessage(chart))
+
+	state = ChartStateRollbackFailed
+	assert.Equal(t, "Chart metal3 upgrade failed, rollback to the previous release failed", state.FormattedMessage(chart))
+
+	state = ChartStateRollbackUnavailable
+	assert.Equal(t, "Chart metal3 upgrade failed, rollback is not possible as the source of the previous release is unknown", state.FormattedMessage(chart))
+
 	state = 99 // non-existing
 	assert.Equal(t, "", state.FormattedMessage(chart))
 }
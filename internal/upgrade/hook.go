@@ -0,0 +1,59 @@
+package upgrade
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// HookJobName returns the name of the Job running the hook for the specified stage.
+func HookJobName(hook *lifecyclev1alpha1.StageHook, stage, nameSuffix string) string {
+	phase := strings.ToLower(string(hook.Phase))
+	stage = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(stage), "-"), "-")
+
+	// Shorten the stage in order to keep the name within the limits of the job-name label.
+	if maxStageLength := validation.DNS1123LabelMaxLength - len(hook.Name) - len(phase) - len(nameSuffix) - 3; len(stage) > maxStageLength {
+		stage = strings.TrimRight(stage[:maxStageLength], "-")
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%s", hook.Name, phase, stage, nameSuffix)
+}
+
+// HookJob creates the Job running the hook based on its template.
+func HookJob(hook *lifecyclev1alpha1.StageHook, name, namespace string, labels map[string]string) *batchv1.Job {
+	template := hook.Template.DeepCopy()
+
+	jobLabels := map[string]string{}
+	for k, v := range template.Labels {
+		jobLabels[k] = v
+	}
+	for k, v := range labels {
+		jobLabels[k] = v
+	}
+	jobLabels[HookLabel] = hook.Name
+
+	// Finished Jobs must not be cleaned up before the upgrade is over,
+	// otherwise the hook would be run again.
+	template.Spec.TTLSecondsAfterFinished = nil
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      jobLabels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}
+}
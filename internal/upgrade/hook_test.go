@@ -0,0 +1,57 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestHookJobName(t *testing.T) {
+	hook := &lifecyclev1alpha1.StageHook{Name: "backup", Phase: lifecyclev1alpha1.HookPhasePre}
+
+	assert.Equal(t, "backup-pre-kubernetes-abcdef", HookJobName(hook, "Kubernetes", "abcdef"))
+	assert.Equal(t, "backup-pre-suse-linux-micro-abcdef", HookJobName(hook, "SUSE Linux Micro", "abcdef"))
+
+	name := HookJobName(hook, "AVeryLongStageNameWhichDoesNotFitIntoTheJobNameAtAll", "abcdef1234")
+	assert.Equal(t, "backup-pre-averylongstagenamewhichdoesnotfitintothej-abcdef1234", name)
+	assert.Len(t, name, 63)
+}
+
+func TestHookJob(t *testing.T) {
+	hook := &lifecyclev1alpha1.StageHook{
+		Name:  "backup",
+		Phase: lifecyclev1alpha1.HookPhasePost,
+		Template: batchv1.JobTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{"app": "backup"},
+				Annotations: map[string]string{"owner": "ops"},
+			},
+			Spec: batchv1.JobSpec{
+				BackoffLimit:            ptr.To[int32](2),
+				TTLSecondsAfterFinished: ptr.To[int32](0),
+			},
+		},
+	}
+
+	job := HookJob(hook, "backup-post-os-abcdef", "upgrade-controller-system", PlanIdentifierLabels("plan", "upgrade-controller-system"))
+
+	assert.Equal(t, "Job", job.Kind)
+	assert.Equal(t, "backup-post-os-abcdef", job.Name)
+	assert.Equal(t, "upgrade-controller-system", job.Namespace)
+	assert.Equal(t, map[string]string{
+		"app":                                  "backup",
+		"lifecycle.suse.com/hook":              "backup",
+		"lifecycle.suse.com/upgrade-plan-name": "plan",
+		"lifecycle.suse.com/upgrade-plan-namespace": "upgrade-controller-system",
+	}, job.Labels)
+	assert.Equal(t, map[string]string{"owner": "ops"}, job.Annotations)
+	assert.Equal(t, ptr.To[int32](2), job.Spec.BackoffLimit)
+	assert.Nil(t, job.Spec.TTLSecondsAfterFinished)
+
+	// The hook template is left intact.
+	assert.Equal(t, ptr.To[int32](0), hook.Template.Spec.TTLSecondsAfterFinished)
+}
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	upgradecattlev1 "github.com/rancher/system-upgrade-controller/pkg/apis/upgrade.cattle.io/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,7 +26,7 @@ func kubernetesUpgradeImage(version string) string {
 	return rke2UpgradeImage
 }
 
-func KubernetesControlPlanePlan(nameSuffix, version string, drain bool, labels map[string]string) *upgradecattlev1.Plan {
+func KubernetesControlPlanePlan(nameSuffix, version string, drain *upgradecattlev1.DrainSpec, labels map[string]string) *upgradecattlev1.Plan {
 	controlPlanePlanName := kubernetesPlanName(controlPlaneKey, version, nameSuffix)
 	upgradeImage := kubernetesUpgradeImage(version)
 
@@ -50,49 +49,20 @@ func KubernetesControlPlanePlan(nameSuffix, version string, drain bool, labels m
 	}
 	controlPlanePlan.Spec.Version = version
 	controlPlanePlan.Spec.Cordon = true
-	controlPlanePlan.Spec.Tolerations = []corev1.Toleration{
-		{
-			Key:      "CriticalAddonsOnly",
-			Operator: "Equal",
-			Value:    "true",
-			Effect:   "NoExecute",
-		},
-		{
-			Key:      ControlPlaneLabel,
-			Operator: "Equal",
-			Value:    "",
-			Effect:   "NoSchedule",
-		},
-		{
-			Key:      "node-role.kubernetes.io/etcd",
-			Operator: "Equal",
-			Value:    "",
-			Effect:   "NoExecute",
-		},
-	}
+	controlPlanePlan.Spec.Tolerations = controlPlaneTolerations()
 
 	return controlPlanePlan
 }
 
-func KubernetesWorkerPlan(nameSuffix, version string, drain bool, labels map[string]string) *upgradecattlev1.Plan {
+func KubernetesWorkerPlan(nameSuffix, version string, batch WorkerBatch, drain *upgradecattlev1.DrainSpec, labels map[string]string) *upgradecattlev1.Plan {
 	controlPlanePlanName := kubernetesPlanName(controlPlaneKey, version, nameSuffix)
-	workerPlanName := kubernetesPlanName(workersKey, version, nameSuffix)
+	workerPlanName := kubernetesPlanName(batch.planKey(), version, nameSuffix)
 	upgradeImage := kubernetesUpgradeImage(version)
 
 	labels["k8s-upgrade"] = "worker"
 	workerPlan := baseUpgradePlan(workerPlanName, drain, labels)
-	workerPlan.Spec.Concurrency = 1
-	workerPlan.Spec.NodeSelector = &metav1.LabelSelector{
-		MatchExpressions: []metav1.LabelSelectorRequirement{
-			{
-				Key:      ControlPlaneLabel,
-				Operator: "NotIn",
-				Values: []string{
-					"true",
-				},
-			},
-		},
-	}
+	workerPlan.Spec.Concurrency = batch.Concurrency
+	workerPlan.Spec.NodeSelector = batch.nodeSelector()
 	workerPlan.Spec.Prepare = &upgradecattlev1.ContainerSpec{
 		Args: []string{
 			"prepare",
@@ -19,7 +19,7 @@ func TestKubernetesControlPlanePlan_RKE2(t *testing.T) {
 		"k8s-upgrade":          "control-plane",
 	}
 
-	upgradePlan := KubernetesControlPlanePlan(planNameSuffix, version, false, addLabels)
+	upgradePlan := KubernetesControlPlanePlan(planNameSuffix, version, nil, addLabels)
 	require.NotNil(t, upgradePlan)
 
 	assert.Equal(t, "Plan", upgradePlan.TypeMeta.Kind)
@@ -84,7 +84,7 @@ func TestKubernetesControlPlanePlan_K3s(t *testing.T) {
 		"k8s-upgrade":          "control-plane",
 	}
 
-	upgradePlan := KubernetesControlPlanePlan(planNameSuffix, version, false, addLabels)
+	upgradePlan := KubernetesControlPlanePlan(planNameSuffix, version, nil, addLabels)
 	require.NotNil(t, upgradePlan)
 
 	assert.Equal(t, "Plan", upgradePlan.TypeMeta.Kind)
@@ -149,7 +149,7 @@ func TestKubernetesWorkerPlan_RKE2(t *testing.T) {
 		"k8s-upgrade":          "worker",
 	}
 
-	upgradePlan := KubernetesWorkerPlan(planNameSuffix, version, false, addLabels)
+	upgradePlan := KubernetesWorkerPlan(planNameSuffix, version, DefaultWorkerBatch, nil, addLabels)
 	require.NotNil(t, upgradePlan)
 
 	assert.Equal(t, "Plan", upgradePlan.TypeMeta.Kind)
@@ -200,7 +200,7 @@ func TestKubernetesWorkerPlan_K3s(t *testing.T) {
 		"k8s-upgrade":          "worker",
 	}
 
-	upgradePlan := KubernetesWorkerPlan(planNameSuffix, version, false, addLabels)
+	upgradePlan := KubernetesWorkerPlan(planNameSuffix, version, DefaultWorkerBatch, nil, addLabels)
 	require.NotNil(t, upgradePlan)
 
 	assert.Equal(t, "Plan", upgradePlan.TypeMeta.Kind)
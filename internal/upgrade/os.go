@@ -68,7 +68,7 @@ func OSUpgradeSecret(nameSuffix string, releaseOS *lifecyclev1alpha1.OperatingSy
 	return secret, nil
 }
 
-func OSControlPlanePlan(nameSuffix, releaseVersion, secretName string, releaseOS *lifecyclev1alpha1.OperatingSystem, drain bool, labels map[string]string) *upgradecattlev1.Plan {
+func OSControlPlanePlan(nameSuffix, releaseVersion, secretName string, releaseOS *lifecyclev1alpha1.OperatingSystem, drain *upgradecattlev1.DrainSpec, labels map[string]string) *upgradecattlev1.Plan {
 	controlPlanePlanName := osPlanName(controlPlaneKey, releaseOS.ZypperID, releaseOS.Version, nameSuffix)
 
 	labels["os-upgrade"] = "control-plane"
@@ -85,56 +85,23 @@ func OSControlPlanePlan(nameSuffix, releaseVersion, secretName string, releaseOS
 			},
 		},
 	}
-	controlPlanePlan.Spec.Tolerations = []corev1.Toleration{
-		{
-			Key:      "CriticalAddonsOnly",
-			Operator: "Equal",
-			Value:    "true",
-			Effect:   "NoExecute",
-		},
-		{
-			Key:      ControlPlaneLabel,
-			Operator: "Equal",
-			Value:    "",
-			Effect:   "NoSchedule",
-		},
-		{
-			Key:      "node-role.kubernetes.io/etcd",
-			Operator: "Equal",
-			Value:    "",
-			Effect:   "NoExecute",
-		},
-	}
+	controlPlanePlan.Spec.Tolerations = controlPlaneTolerations()
 
 	return controlPlanePlan
 }
 
-func OSWorkerPlan(nameSuffix, releaseVersion, secretName string, releaseOS *lifecyclev1alpha1.OperatingSystem, drain bool, labels map[string]string) *upgradecattlev1.Plan {
-	workerPlanName := osPlanName(workersKey, releaseOS.ZypperID, releaseOS.Version, nameSuffix)
+func OSWorkerPlan(nameSuffix, releaseVersion, secretName string, releaseOS *lifecyclev1alpha1.OperatingSystem, batch WorkerBatch, drain *upgradecattlev1.DrainSpec, labels map[string]string) *upgradecattlev1.Plan {
+	workerPlanName := osPlanName(batch.planKey(), releaseOS.ZypperID, releaseOS.Version, nameSuffix)
 
 	labels["os-upgrade"] = "worker"
 	workerPlan := baseOSPlan(workerPlanName, releaseVersion, secretName, drain, labels)
-	workerPlan.Spec.Concurrency = 1
-	workerPlan.Spec.NodeSelector = &metav1.LabelSelector{
-		MatchExpressions: []metav1.LabelSelectorRequirement{
-			{
-				Key:      ControlPlaneLabel,
-				Operator: "NotIn",
-				Values: []string{
-					"true",
-				},
-			},
-		},
-	}
+	workerPlan.Spec.Concurrency = batch.Concurrency
+	workerPlan.Spec.NodeSelector = batch.nodeSelector()
 
 	return workerPlan
 }
 
-func baseOSPlan(planName, releaseVersion, secretName string, drain bool, labels map[string]string) *upgradecattlev1.Plan {
-	const (
-		planImage = "registry.suse.com/bci/bci-base:15.6"
-	)
-
+func baseOSPlan(planName, releaseVersion, secretName string, drain *upgradecattlev1.DrainSpec, labels map[string]string) *upgradecattlev1.Plan {
 	baseOSplan := baseUpgradePlan(planName, drain, labels)
 
 	secretPathRelativeToHost := fmt.Sprintf("/run/system-upgrade/secrets/%s", secretName)
@@ -152,7 +119,7 @@ func baseOSPlan(planName, releaseVersion, secretName string, drain bool, labels
 	baseOSplan.Spec.JobActiveDeadlineSecs = &deadlineSecs
 
 	baseOSplan.Spec.Upgrade = &upgradecattlev1.ContainerSpec{
-		Image:   planImage,
+		Image:   hostCommandImage,
 		Command: []string{"chroot", "/host"},
 		Args:    []string{"sh", filepath.Join(secretPathRelativeToHost, scriptName)},
 	}
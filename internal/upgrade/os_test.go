@@ -59,7 +59,7 @@ func TestOSControlPlanePlan(t *testing.T) {
 		"os-upgrade":           "control-plane",
 	}
 
-	upgradePlan := OSControlPlanePlan(planNameSuffix, releaseVersion, secretName, os, false, addLabels)
+	upgradePlan := OSControlPlanePlan(planNameSuffix, releaseVersion, secretName, os, nil, addLabels)
 	require.NotNil(t, upgradePlan)
 
 	assert.Equal(t, "Plan", upgradePlan.TypeMeta.Kind)
@@ -131,7 +131,7 @@ func TestOSWorkerPlan(t *testing.T) {
 		"os-upgrade":           "worker",
 	}
 
-	upgradePlan := OSWorkerPlan(planNameSuffix, releaseVersion, secretName, os, false, addLabels)
+	upgradePlan := OSWorkerPlan(planNameSuffix, releaseVersion, secretName, os, DefaultWorkerBatch, nil, addLabels)
 	require.NotNil(t, upgradePlan)
 
 	assert.Equal(t, "Plan", upgradePlan.TypeMeta.Kind)
@@ -0,0 +1,85 @@
+package upgrade
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CanaryBatch is the name of the worker batch upgraded ahead of the rest of the worker nodes.
+	CanaryBatch = "canary"
+
+	// CanarySoakStartAnnotation holds the time at which the canary nodes have been upgraded.
+	CanarySoakStartAnnotation = "lifecycle.suse.com/canary-soak-start"
+)
+
+// WorkerBatch describes the subset of worker nodes upgraded by a single SUC Plan.
+type WorkerBatch struct {
+	// Name distinguishes the plans of the different batches.
+	// The default batch is unnamed.
+	Name string
+	// Hostnames limits the batch to the specified nodes only.
+	Hostnames []string
+	// ExcludedHostnames excludes the specified nodes from the batch.
+	ExcludedHostnames []string
+	// Concurrency is the number of nodes which are upgraded at the same time.
+	Concurrency int64
+}
+
+// DefaultWorkerBatch targets all worker nodes, one node at a time.
+var DefaultWorkerBatch = WorkerBatch{Concurrency: 1}
+
+func (b WorkerBatch) planKey() string {
+	if b.Name == "" {
+		return workersKey
+	}
+
+	return workersKey + "-" + b.Name
+}
+
+func (b WorkerBatch) nodeSelector() *metav1.LabelSelector {
+	selector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      ControlPlaneLabel,
+				Operator: "NotIn",
+				Values: []string{
+					"true",
+				},
+			},
+		},
+	}
+
+	if len(b.Hostnames) != 0 {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      corev1.LabelHostname,
+			Operator: "In",
+			Values:   b.Hostnames,
+		})
+	}
+
+	if len(b.ExcludedHostnames) != 0 {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      corev1.LabelHostname,
+			Operator: "NotIn",
+			Values:   b.ExcludedHostnames,
+		})
+	}
+
+	return selector
+}
+
+// BatchHostnames returns the hostnames which the node selector of a worker batch plan is limited to.
+func BatchHostnames(selector *metav1.LabelSelector) []string {
+	if selector == nil {
+		return nil
+	}
+
+	for _, requirement := range selector.MatchExpressions {
+		if requirement.Key == corev1.LabelHostname && requirement.Operator == metav1.LabelSelectorOpIn {
+			return requirement.Values
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lifecyclev1alpha1 "github.com/suse-edge/upgrade-controller/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOSWorkerPlan_CanaryBatch(t *testing.T) {
+	os := &lifecyclev1alpha1.OperatingSystem{
+		Version:  "6.0",
+		ZypperID: "SL-Micro",
+	}
+
+	batch := WorkerBatch{
+		Name:        CanaryBatch,
+		Hostnames:   []string{"worker-1", "worker-2"},
+		Concurrency: 2,
+	}
+
+	upgradePlan := OSWorkerPlan(planNameSuffix, releaseVersion, "some-secret", os, batch, nil, map[string]string{})
+	require.NotNil(t, upgradePlan)
+
+	assert.Equal(t, "workers-canary-sl-micro-6-0-abcdef", upgradePlan.ObjectMeta.Name)
+	assert.EqualValues(t, 2, upgradePlan.Spec.Concurrency)
+
+	expectedSelector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      "node-role.kubernetes.io/control-plane",
+				Operator: "NotIn",
+				Values:   []string{"true"},
+			},
+			{
+				Key:      "kubernetes.io/hostname",
+				Operator: "In",
+				Values:   []string{"worker-1", "worker-2"},
+			},
+		},
+	}
+	assert.Equal(t, expectedSelector, upgradePlan.Spec.NodeSelector)
+}
+
+func TestKubernetesWorkerPlan_ExcludedHostnames(t *testing.T) {
+	batch := WorkerBatch{
+		ExcludedHostnames: []string{"worker-1"},
+		Concurrency:       5,
+	}
+
+	upgradePlan := KubernetesWorkerPlan(planNameSuffix, "v1.30.2+rke2r1", batch, nil, map[string]string{})
+	require.NotNil(t, upgradePlan)
+
+	assert.Equal(t, "workers-v1-30-2-rke2r1-abcdef", upgradePlan.ObjectMeta.Name)
+	assert.EqualValues(t, 5, upgradePlan.Spec.Concurrency)
+
+	expectedSelector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      "node-role.kubernetes.io/control-plane",
+				Operator: "NotIn",
+				Values:   []string{"true"},
+			},
+			{
+				Key:      "kubernetes.io/hostname",
+				Operator: "NotIn",
+				Values:   []string{"worker-1"},
+			},
+		},
+	}
+	assert.Equal(t, expectedSelector, upgradePlan.Spec.NodeSelector)
+}
+
+func TestBatchHostnames(t *testing.T) {
+	assert.Nil(t, BatchHostnames(nil))
+
+	batch := WorkerBatch{Name: "rack-1", Hostnames: []string{"worker-1", "worker-2"}}
+	assert.Equal(t, []string{"worker-1", "worker-2"}, BatchHostnames(batch.nodeSelector()))
+
+	batch = WorkerBatch{ExcludedHostnames: []string{"worker-1"}}
+	assert.Nil(t, BatchHostnames(batch.nodeSelector()))
+}